@@ -0,0 +1,207 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package breach looks up passwords in the Pwned Passwords lists of Have I
+// Been Pwned <https://haveibeenpwned.com/Passwords>, downloaded locally.
+//
+// A list is a text file with one "HASH:COUNT" line per breached password,
+// sorted by hash. The hashes are either SHA-1 or NTLM hashes of the
+// passwords. The file is binary searched, so it is never loaded in memory:
+//
+//	f, err := breach.Open("pwned-passwords-sha1-ordered-by-hash-v8.txt")
+//	count, err := f.Lookup("P@ssw0rd")
+package breach
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/crypto/md4"
+)
+
+// ErrInvalidFile is the error returned when a file is not a sorted Pwned
+// Passwords list.
+var ErrInvalidFile = errors.New("invalid Pwned Passwords file")
+
+// HashType is the type of the hashes of a Pwned Passwords list.
+type HashType string
+
+const (
+	// SHA1 is the hex-encoded SHA-1 hash of the UTF-8 password.
+	SHA1 HashType = "SHA-1"
+	// NTLM is the hex-encoded MD4 hash of the UTF-16LE password.
+	NTLM HashType = "NTLM"
+)
+
+// maxLine is the length of the longest line read from a list.
+const maxLine = 128
+
+// File is a Pwned Passwords list opened for lookups. It is safe for
+// concurrent use.
+type File struct {
+	f    *os.File
+	size int64
+	typ  HashType
+}
+
+// Open opens the Pwned Passwords list at path. The type of its hashes is
+// detected from its first line.
+func Open(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	file := &File{f: f, size: fi.Size()}
+	first, _, err := file.line(0)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	switch len(first) {
+	case 2 * sha1.Size:
+		file.typ = SHA1
+	case 32:
+		file.typ = NTLM
+	default:
+		f.Close()
+		return nil, fmt.Errorf("%w: %s: unknown hash %q", ErrInvalidFile, path, first)
+	}
+	return file, nil
+}
+
+// Close closes the file.
+func (f *File) Close() error {
+	return f.f.Close()
+}
+
+// Type returns the type of the hashes of the file.
+func (f *File) Type() HashType {
+	return f.typ
+}
+
+// Hash returns the hash of password as found in the file.
+func (f *File) Hash(password string) string {
+	if f.typ == NTLM {
+		return NTLMHash(password)
+	}
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// Lookup returns the number of times password appears in breaches, zero
+// if it is not in the file.
+//
+// The lines are binary searched by their offset: the search range always
+// starts at the beginning of a line, and the line following the middle of
+// the range is compared to the hash.
+func (f *File) Lookup(password string) (int, error) {
+	hash := f.Hash(password)
+
+	lo, hi := int64(0), f.size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		start := lo
+		if mid > lo {
+			var err error
+			if start, err = f.nextLine(mid); err != nil {
+				return 0, err
+			}
+		}
+		if start >= hi {
+			hi = mid
+			continue
+		}
+
+		lineHash, count, err := f.line(start)
+		if err != nil {
+			return 0, err
+		}
+		switch c := strings.Compare(strings.ToUpper(lineHash), hash); {
+		case c == 0:
+			n, err := strconv.Atoi(count)
+			if err != nil {
+				return 0, fmt.Errorf("%w: invalid count %q of %s", ErrInvalidFile, count, lineHash)
+			}
+			return n, nil
+		case c < 0:
+			if lo, err = f.nextLine(start + 1); err != nil {
+				return 0, err
+			}
+		default:
+			hi = mid
+		}
+	}
+	return 0, nil
+}
+
+// nextLine returns the offset of the first line starting at off or after.
+func (f *File) nextLine(off int64) (int64, error) {
+	buf := make([]byte, maxLine)
+	for pos := off - 1; pos < f.size; pos += int64(len(buf)) {
+		n, err := f.f.ReadAt(buf, pos)
+		if i := bytes.IndexByte(buf[:n], '\n'); i >= 0 {
+			return pos + int64(i) + 1, nil
+		}
+		if n < len(buf) && err != nil {
+			break
+		}
+	}
+	return f.size, nil
+}
+
+// line returns the hash and the count of the line starting at off.
+func (f *File) line(off int64) (hash, count string, err error) {
+	buf := make([]byte, maxLine)
+	n, err := f.f.ReadAt(buf, off)
+	if n == 0 && err != nil {
+		return "", "", fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	line := buf[:n]
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	} else if off+int64(n) < f.size {
+		return "", "", fmt.Errorf("%w: line at offset %d longer than %d bytes", ErrInvalidFile, off, maxLine)
+	}
+
+	hash, count, ok := strings.Cut(strings.TrimRight(string(line), "\r"), ":")
+	if !ok {
+		return "", "", fmt.Errorf("%w: line %q at offset %d is not HASH:COUNT", ErrInvalidFile, line, off)
+	}
+	return hash, count, nil
+}
+
+// NTLMHash returns the NTLM hash of password, as found in the NTLM lists.
+func NTLMHash(password string) string {
+	units := utf16.Encode([]rune(password))
+	b := make([]byte, 2*len(units))
+	for i, u := range units {
+		b[2*i], b[2*i+1] = byte(u), byte(u>>8)
+	}
+	h := md4.New()
+	h.Write(b)
+	return strings.ToUpper(hex.EncodeToString(h.Sum(nil)))
+}
@@ -0,0 +1,121 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package breach
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// writeList writes a sorted list of the hashes of the passwords, each
+// breached i+1 times, and returns its path.
+func writeList(t *testing.T, hash func(string) string, passwords []string, eol string) string {
+	t.Helper()
+
+	lines := make([]string, len(passwords))
+	for i, p := range passwords {
+		lines[i] = fmt.Sprintf("%s:%d", hash(p), i+1)
+	}
+	sort.Strings(lines)
+
+	path := filepath.Join(t.TempDir(), "pwned.txt")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, eol)+eol), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLookup(t *testing.T) {
+	t.Parallel()
+
+	var passwords []string
+	for i := 0; i < 1000; i++ {
+		passwords = append(passwords, fmt.Sprintf("password%d", i))
+	}
+	sha1Hash := (&File{typ: SHA1}).Hash
+
+	for _, tc := range []struct {
+		name string
+		hash func(string) string
+		typ  HashType
+		eol  string
+	}{
+		{"sha1", sha1Hash, SHA1, "\r\n"},
+		{"ntlm", NTLMHash, NTLM, "\n"},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := Open(writeList(t, tc.hash, passwords, tc.eol))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			if f.Type() != tc.typ {
+				t.Errorf("got type %s, want %s", f.Type(), tc.typ)
+			}
+
+			for i, p := range passwords {
+				n, err := f.Lookup(p)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if n != i+1 {
+					t.Errorf("%q: got count %d, want %d", p, n, i+1)
+				}
+			}
+			for _, p := range []string{"", "password", "password1000", "correct horse"} {
+				if n, err := f.Lookup(p); n != 0 || err != nil {
+					t.Errorf("%q should not be found, got %d, %v", p, n, err)
+				}
+			}
+		})
+	}
+}
+
+func TestHashes(t *testing.T) {
+	t.Parallel()
+
+	if got, want := (&File{typ: SHA1}).Hash("password"), "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8"; got != want {
+		t.Errorf("got SHA-1 %s, want %s", got, want)
+	}
+	if got, want := NTLMHash("password"), "8846F7EAEE8FB117AD06BDD830B7586C"; got != want {
+		t.Errorf("got NTLM %s, want %s", got, want)
+	}
+
+	if got, want := NTLMHash(""), "31D6CFE0D16AE931B73C59D7E0C089C0"; got != want {
+		t.Errorf("got NTLM of the empty password %s, want %s", got, want)
+	}
+}
+
+func TestOpenInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, content := range []string{"", "not a hash\n", "ABCDEF:1\n"} {
+		path := filepath.Join(t.TempDir(), "pwned.txt")
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Open(path); !errors.Is(err, ErrInvalidFile) {
+			t.Errorf("%q: %v should be %q", content, err, ErrInvalidFile)
+		}
+	}
+}
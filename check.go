@@ -0,0 +1,98 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/term"
+
+	"github.com/ntk148v/pwgenie/strength"
+)
+
+// readPassword reads a password from stdin. On a terminal it prints the
+// prompt and disables echo, otherwise it reads the first line.
+func readPassword(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		fmt.Fprint(os.Stderr, prompt)
+		b, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		return string(b), err
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// printCheck prints the strength estimate of a password.
+func printCheck(w io.Writer, res strength.Result) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Guesses:\t%.3g (10^%.2f)\n", res.Guesses, res.GuessesLog10)
+	fmt.Fprintf(tw, "Score:\t%d/4\n", res.Score)
+	if res.Blocked {
+		fmt.Fprintln(tw, "Blocklist:\tcommon password, do not use")
+	}
+	fmt.Fprintln(tw, "\nCrack times:")
+	for _, c := range res.CrackTimes {
+		fmt.Fprintf(tw, "  %s\t%s\n", c.Name, c)
+	}
+	fmt.Fprintln(tw, "\nPatterns:")
+	for _, m := range res.Sequence {
+		fmt.Fprintf(tw, "  %s\t%q\t%s\n", m.Pattern, m.Token, describeMatch(m))
+	}
+	_ = tw.Flush()
+}
+
+// describeMatch returns the details of a match.
+func describeMatch(m strength.Match) string {
+	var details string
+	switch m.Pattern {
+	case strength.PatternDictionary:
+		details = fmt.Sprintf("%s, rank %d", m.Dictionary, m.Rank)
+		if m.MatchedWord != strings.ToLower(m.Token) {
+			details += fmt.Sprintf(", word %q", m.MatchedWord)
+		}
+		if m.Reversed {
+			details += ", reversed"
+		}
+		if m.L33t {
+			details += ", leetspeak"
+		}
+	case strength.PatternSpatial:
+		details = fmt.Sprintf("%s keyboard, %d turns", m.Graph, m.Turns)
+	case strength.PatternRepeat:
+		details = fmt.Sprintf("%q repeated %d times", m.BaseToken, m.RepeatCount)
+	case strength.PatternSequence:
+		details = m.SequenceName
+		if !m.Ascending {
+			details += ", descending"
+		}
+	case strength.PatternDate:
+		details = fmt.Sprintf("%04d-%02d-%02d", m.Year, m.Month, m.Day)
+	case strength.PatternYear:
+		details = fmt.Sprintf("%d", m.Year)
+	}
+
+	return fmt.Sprintf("%s\t%.3g guesses", details, m.Guesses)
+}
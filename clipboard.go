@@ -0,0 +1,329 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+	"golang.org/x/term"
+)
+
+// errUnreadableClipboard is the error returned by the clipboards which can
+// be written but not read, such as OSC 52.
+var errUnreadableClipboard = errors.New("clipboard cannot be read")
+
+// clipboardBackend is a clipboard the passwords are copied to.
+//
+// Clipboard managers such as Klipper and cliphist skip the entries which
+// also offer the x-kde-passwordManagerHint MIME type with the value
+// "secret". The Wayland backend offers it with wl-copy --sensitive, when
+// the installed wl-copy lists that option. The other backends cannot:
+// an entry offers every type from the same owner, while xclip -t offers a
+// single type, which must be text for the password to be pasted, and xsel
+// offers text only. tmux buffers and OSC 52 sequences are text without
+// types, and the system clipboard runs the same commands on X11, or has no
+// such hint.
+type clipboardBackend interface {
+	// Write replaces the content of the clipboard with text.
+	Write(text string) error
+	// Read returns the content of the clipboard, or
+	// errUnreadableClipboard.
+	Read() (string, error)
+}
+
+// clipboardBackends are the backends of -clipboard-backend by name.
+var clipboardBackends = map[string]func() clipboardBackend{
+	"system":          newSystemClipboard,
+	"x11":             func() clipboardBackend { return newX11Clipboard("clipboard") },
+	"x11-primary":     func() clipboardBackend { return newX11Clipboard("primary") },
+	"wayland":         func() clipboardBackend { return newWaylandClipboard(false) },
+	"wayland-primary": func() clipboardBackend { return newWaylandClipboard(true) },
+	"tmux":            newTmuxClipboard,
+	"osc52":           newOSC52Clipboard,
+}
+
+// clipboardBackendNames returns the sorted names accepted by
+// -clipboard-backend.
+func clipboardBackendNames() []string {
+	names := []string{"auto"}
+	for name := range clipboardBackends {
+		names = append(names, name)
+	}
+	sort.Strings(names[1:])
+	return names
+}
+
+// newClipboard returns the clipboard backend with the given name, or the
+// backend usable in this session for auto.
+func newClipboard(name string) (clipboardBackend, error) {
+	if name == "auto" {
+		return autoClipboard(), nil
+	}
+	newBackend, ok := clipboardBackends[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown clipboard backend %q, want one of %s", name, strings.Join(clipboardBackendNames(), ", "))
+	}
+	return newBackend(), nil
+}
+
+// autoClipboard returns the first backend usable in this session: OSC 52
+// over SSH without a display, as it reaches the clipboard of the terminal
+// of the user, then Wayland, X11, tmux, the system clipboard on macOS and
+// Windows and OSC 52 in any other terminal.
+func autoClipboard() clipboardBackend {
+	switch {
+	case isSSH() && !hasDisplay() && ttyAvailable():
+		return newOSC52Clipboard()
+	case os.Getenv("WAYLAND_DISPLAY") != "" && hasCommand("wl-copy"):
+		return newWaylandClipboard(false)
+	case os.Getenv("DISPLAY") != "" && (hasCommand("xclip") || hasCommand("xsel")):
+		return newX11Clipboard("clipboard")
+	case os.Getenv("TMUX") != "" && hasCommand("tmux"):
+		return newTmuxClipboard()
+	case runtime.GOOS != "darwin" && runtime.GOOS != "windows" && ttyAvailable():
+		return newOSC52Clipboard()
+	default:
+		return newSystemClipboard()
+	}
+}
+
+// systemClipboard is the clipboard of the operating system, through
+// github.com/atotto/clipboard.
+type systemClipboard struct{}
+
+func newSystemClipboard() clipboardBackend { return systemClipboard{} }
+
+func (systemClipboard) Write(text string) error { return clipboard.WriteAll(text) }
+
+func (systemClipboard) Read() (string, error) { return clipboard.ReadAll() }
+
+// commandClipboard is a clipboard written and read by external commands,
+// which read the text on stdin and print it on stdout.
+type commandClipboard struct {
+	write, read []string
+}
+
+// Write implements clipboardBackend.
+func (c commandClipboard) Write(text string) error {
+	cmd := exec.Command(c.write[0], c.write[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	return cmd.Run()
+}
+
+// Read implements clipboardBackend.
+func (c commandClipboard) Read() (string, error) {
+	out, err := exec.Command(c.read[0], c.read[1:]...).Output()
+	return string(out), err
+}
+
+// newX11Clipboard returns the X11 clipboard of the given selection,
+// clipboard or primary, through xclip or xsel.
+func newX11Clipboard(selection string) clipboardBackend {
+	if !hasCommand("xclip") && hasCommand("xsel") {
+		return commandClipboard{
+			write: []string{"xsel", "--" + selection, "--input"},
+			read:  []string{"xsel", "--" + selection, "--output"},
+		}
+	}
+	return commandClipboard{
+		write: []string{"xclip", "-selection", selection, "-in"},
+		read:  []string{"xclip", "-selection", selection, "-out"},
+	}
+}
+
+// newWaylandClipboard returns the Wayland clipboard, or the primary
+// selection, through wl-copy and wl-paste.
+func newWaylandClipboard(primary bool) clipboardBackend {
+	c := commandClipboard{
+		write: []string{"wl-copy"},
+		read:  []string{"wl-paste", "--no-newline"},
+	}
+	if primary {
+		c.write = append(c.write, "--primary")
+		c.read = append(c.read, "--primary")
+	}
+	if waylandSensitive() {
+		c.write = append(c.write, "--sensitive")
+	}
+	return c
+}
+
+// waylandSensitive reports whether wl-copy has the --sensitive option,
+// which offers the x-kde-passwordManagerHint MIME type next to the text.
+func waylandSensitive() bool {
+	out, err := exec.Command("wl-copy", "--help").CombinedOutput()
+	return err == nil && strings.Contains(string(out), "--sensitive")
+}
+
+// newTmuxClipboard returns the tmux paste buffer. tmux forwards it to the
+// clipboard of the terminal when its set-clipboard option is on.
+func newTmuxClipboard() clipboardBackend {
+	return commandClipboard{
+		write: []string{"tmux", "load-buffer", "-w", "-"},
+		read:  []string{"tmux", "save-buffer", "-"},
+	}
+}
+
+// osc52Clipboard is the clipboard of the terminal emulator, written with
+// the OSC 52 escape sequence. It works over SSH, as the sequence travels
+// with the output to the terminal of the user, but it cannot be read back.
+type osc52Clipboard struct {
+	tmux bool
+}
+
+func newOSC52Clipboard() clipboardBackend {
+	return osc52Clipboard{tmux: os.Getenv("TMUX") != ""}
+}
+
+// Write implements clipboardBackend. Inside tmux, the sequence is wrapped
+// in a passthrough sequence to reach the outer terminal.
+func (c osc52Clipboard) Write(text string) error {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	if c.tmux {
+		seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer tty.Close()
+	_, err = io.WriteString(tty, seq)
+	return err
+}
+
+// Read implements clipboardBackend.
+func (osc52Clipboard) Read() (string, error) {
+	return "", errUnreadableClipboard
+}
+
+// copyToClipboard writes pass to the clipboard. With a positive clearAfter,
+// it then waits and clears the clipboard when the time is up, unless the
+// clipboard was changed meanwhile; clipboards which cannot be read are
+// cleared anyway. On a terminal, a countdown is shown and Ctrl-C cancels
+// the clearing.
+func copyToClipboard(cb clipboardBackend, pass string, clearAfter time.Duration) {
+	if err := cb.Write(pass); err != nil || clearAfter <= 0 {
+		return
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	clearLater(cb, pass, clearAfter, interrupt, os.Stderr, term.IsTerminal(int(os.Stderr.Fd())))
+}
+
+// clearLater clears the clipboard with clearClipboard after clearAfter,
+// unless interrupt receives a signal first. When interactive, the
+// countdown and the outcome are written to status; failures are written
+// in any case.
+func clearLater(cb clipboardBackend, pass string, clearAfter time.Duration, interrupt <-chan os.Signal, status io.Writer, interactive bool) {
+	deadline := time.Now().Add(clearAfter)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	timer := time.NewTimer(clearAfter)
+	defer timer.Stop()
+
+	// report replaces the countdown with msg.
+	report := func(msg string) {
+		if interactive {
+			fmt.Fprintf(status, "\r%-50s\n", msg)
+		} else {
+			fmt.Fprintln(status, msg)
+		}
+	}
+
+	for {
+		if interactive {
+			left := time.Until(deadline).Round(time.Second)
+			fmt.Fprintf(status, "\rClearing the clipboard in %s, Ctrl-C to cancel ", left)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-interrupt:
+			if interactive {
+				report("Clipboard clearing cancelled")
+			}
+			return
+		case <-timer.C:
+			msg, err := clearClipboard(cb, pass)
+			if err != nil {
+				report("warning: " + err.Error())
+			} else if interactive {
+				report(msg)
+			}
+			return
+		}
+	}
+}
+
+// clearClipboard clears the clipboard if it still holds pass, or cannot be
+// read, and describes what it did.
+func clearClipboard(cb clipboardBackend, pass string) (string, error) {
+	current, err := cb.Read()
+	switch {
+	case errors.Is(err, errUnreadableClipboard):
+	case err != nil:
+		return "", fmt.Errorf("clipboard not cleared, it cannot be read: %w", err)
+	case strings.TrimSuffix(current, "\n") != pass:
+		// Do not wipe what the user copied since
+		return "Clipboard not cleared, its content changed", nil
+	}
+
+	if err := cb.Write(""); err != nil {
+		return "", fmt.Errorf("clipboard not cleared: %w", err)
+	}
+	return "Clipboard cleared", nil
+}
+
+// isSSH reports whether pwgenie runs in an SSH session.
+func isSSH() bool {
+	return os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != ""
+}
+
+// hasDisplay reports whether a graphical session is available.
+func hasDisplay() bool {
+	return os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != ""
+}
+
+// ttyAvailable reports whether the controlling terminal can be written. It
+// is a variable for the tests.
+var ttyAvailable = func() bool {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return false
+	}
+	tty.Close()
+	return true
+}
+
+// hasCommand reports whether the command is in the PATH.
+func hasCommand(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClipboard is a clipboard backend in memory.
+type fakeClipboard struct {
+	mu       sync.Mutex
+	content  string
+	readErr  error
+	writeErr error
+}
+
+func (c *fakeClipboard) Write(text string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.writeErr != nil {
+		return c.writeErr
+	}
+	c.content = text
+	return nil
+}
+
+func (c *fakeClipboard) Read() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.content, c.readErr
+}
+
+func TestClearClipboard(t *testing.T) {
+	t.Parallel()
+
+	failure := errors.New("failure")
+	tests := []struct {
+		name    string
+		cb      *fakeClipboard
+		msg     string
+		err     bool
+		content string
+	}{
+		{"unchanged", &fakeClipboard{content: "secret"}, "Clipboard cleared", false, ""},
+		{"newline", &fakeClipboard{content: "secret\n"}, "Clipboard cleared", false, ""},
+		{"changed", &fakeClipboard{content: "other"}, "Clipboard not cleared, its content changed", false, "other"},
+		{"unreadable", &fakeClipboard{content: "other", readErr: errUnreadableClipboard}, "Clipboard cleared", false, ""},
+		{"read_error", &fakeClipboard{content: "secret", readErr: failure}, "", true, "secret"},
+		{"write_error", &fakeClipboard{content: "secret", writeErr: failure}, "", true, "secret"},
+	}
+	for _, tt := range tests {
+		msg, err := clearClipboard(tt.cb, "secret")
+		if msg != tt.msg || (err != nil) != tt.err {
+			t.Errorf("%s: got %q, %v, want %q", tt.name, msg, err, tt.msg)
+		}
+		if err != nil && !errors.Is(err, failure) {
+			t.Errorf("%s: %v should wrap %v", tt.name, err, failure)
+		}
+		if tt.cb.content != tt.content {
+			t.Errorf("%s: the clipboard holds %q, want %q", tt.name, tt.cb.content, tt.content)
+		}
+	}
+}
+
+func TestClearLater(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		cb          *fakeClipboard
+		interrupt   bool
+		interactive bool
+		status      string
+		content     string
+	}{
+		{"cleared", &fakeClipboard{content: "secret"}, false, true, "Clipboard cleared", ""},
+		{"quiet", &fakeClipboard{content: "secret"}, false, false, "", ""},
+		{"changed", &fakeClipboard{content: "other"}, false, true, "Clipboard not cleared, its content changed", "other"},
+		{"failure", &fakeClipboard{content: "secret", readErr: errors.New("no display")}, false, false, "warning: clipboard not cleared, it cannot be read: no display", "secret"},
+		{"cancelled", &fakeClipboard{content: "secret"}, true, true, "Clipboard clearing cancelled", "secret"},
+	}
+	for _, tt := range tests {
+		interrupt := make(chan os.Signal, 1)
+		if tt.interrupt {
+			interrupt <- os.Interrupt
+		}
+		var status strings.Builder
+		clearLater(tt.cb, "secret", 10*time.Millisecond, interrupt, &status, tt.interactive)
+
+		got := status.String()
+		if tt.interactive {
+			if !strings.HasPrefix(got, "\rClearing the clipboard in ") {
+				t.Errorf("%s: missing countdown in %q", tt.name, got)
+			}
+			got = got[strings.LastIndex(got, "\r")+1:]
+		}
+		if strings.TrimSpace(got) != tt.status {
+			t.Errorf("%s: got status %q, want %q", tt.name, got, tt.status)
+		}
+		if tt.cb.content != tt.content {
+			t.Errorf("%s: the clipboard holds %q, want %q", tt.name, tt.cb.content, tt.content)
+		}
+	}
+}
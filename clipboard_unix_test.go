@@ -0,0 +1,181 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// fakeCommands sets PATH to a directory of shell scripts named after the
+// given commands and returns the directory. The scripts store the text of
+// the clipboard in the file "content" of the directory; the script of
+// wl-copy lists --sensitive in its help if sensitive is set.
+func fakeCommands(t *testing.T, sensitive bool, names ...string) string {
+	t.Helper()
+	cat, err := exec.LookPath("cat")
+	if err != nil {
+		t.Skip(err)
+	}
+	dir := t.TempDir()
+	for _, name := range names {
+		script := "#!/bin/sh\n" + cat + " > \"" + filepath.Join(dir, "content") + "\"\n"
+		switch name {
+		case "wl-paste", "tmux":
+			script = "#!/bin/sh\n" + cat + " \"" + filepath.Join(dir, "content") + "\"\n"
+		case "wl-copy":
+			if sensitive {
+				script = "#!/bin/sh\n[ \"$1\" = --help ] && echo '  -s, --sensitive' && exit\n" + script[len("#!/bin/sh\n"):]
+			}
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+	return dir
+}
+
+// The tests of the backends set the environment and ttyAvailable, so they
+// do not run in parallel.
+func TestAutoClipboard(t *testing.T) {
+	tty := ttyAvailable
+	defer func() { ttyAvailable = tty }()
+
+	tests := []struct {
+		name     string
+		env      map[string]string
+		commands []string
+		tty      bool
+		want     clipboardBackend
+	}{
+		{
+			name: "ssh",
+			env:  map[string]string{"SSH_CONNECTION": "10.0.0.1 22 10.0.0.2 22"},
+			tty:  true,
+			want: osc52Clipboard{},
+		},
+		{
+			name:     "ssh_display",
+			env:      map[string]string{"SSH_TTY": "/dev/pts/0", "DISPLAY": "localhost:10.0"},
+			commands: []string{"xclip"},
+			tty:      true,
+			want:     commandClipboard{write: []string{"xclip", "-selection", "clipboard", "-in"}, read: []string{"xclip", "-selection", "clipboard", "-out"}},
+		},
+		{
+			name:     "wayland",
+			env:      map[string]string{"WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":0"},
+			commands: []string{"wl-copy", "xclip"},
+			want:     commandClipboard{write: []string{"wl-copy"}, read: []string{"wl-paste", "--no-newline"}},
+		},
+		{
+			name:     "wayland_without_wl_copy",
+			env:      map[string]string{"WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":0"},
+			commands: []string{"xsel"},
+			want:     commandClipboard{write: []string{"xsel", "--clipboard", "--input"}, read: []string{"xsel", "--clipboard", "--output"}},
+		},
+		{
+			name:     "tmux",
+			env:      map[string]string{"TMUX": "/tmp/tmux-1000/default,1,0"},
+			commands: []string{"tmux"},
+			tty:      true,
+			want:     commandClipboard{write: []string{"tmux", "load-buffer", "-w", "-"}, read: []string{"tmux", "save-buffer", "-"}},
+		},
+		{
+			name: "tmux_without_command",
+			env:  map[string]string{"TMUX": "/tmp/tmux-1000/default,1,0"},
+			tty:  true,
+			want: osc52Clipboard{tmux: true},
+		},
+		{
+			name: "terminal",
+			tty:  true,
+			want: osc52Clipboard{},
+		},
+		{
+			name: "no_terminal",
+			want: systemClipboard{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"SSH_TTY", "SSH_CONNECTION", "DISPLAY", "WAYLAND_DISPLAY", "TMUX"} {
+				t.Setenv(name, "")
+				os.Unsetenv(name)
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			fakeCommands(t, false, tt.commands...)
+			ttyAvailable = func() bool { return tt.tty }
+
+			if got := autoClipboard(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewClipboard(t *testing.T) {
+	t.Setenv("TMUX", "")
+	fakeCommands(t, false, "xsel")
+
+	tests := map[string]clipboardBackend{
+		"x11-primary":     commandClipboard{write: []string{"xsel", "--primary", "--input"}, read: []string{"xsel", "--primary", "--output"}},
+		"wayland-primary": commandClipboard{write: []string{"wl-copy", "--primary"}, read: []string{"wl-paste", "--no-newline", "--primary"}},
+		"osc52":           osc52Clipboard{},
+		"system":          systemClipboard{},
+	}
+	for name, want := range tests {
+		got, err := newClipboard(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %#v, want %#v", name, got, want)
+		}
+	}
+
+	if _, err := newClipboard("klipper"); err == nil {
+		t.Error("an unknown backend should be an error")
+	}
+}
+
+func TestWaylandClipboard(t *testing.T) {
+	dir := fakeCommands(t, true, "wl-copy", "wl-paste")
+
+	cb := newWaylandClipboard(false)
+	if want := []string{"wl-copy", "--sensitive"}; !reflect.DeepEqual(cb.(commandClipboard).write, want) {
+		t.Errorf("got %q, want %q", cb.(commandClipboard).write, want)
+	}
+
+	if err := cb.Write("secret"); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := os.ReadFile(filepath.Join(dir, "content")); err != nil || string(b) != "secret" {
+		t.Fatalf("the clipboard holds %q, %v", b, err)
+	}
+	if msg, err := clearClipboard(cb, "secret"); err != nil || msg != "Clipboard cleared" {
+		t.Errorf("got %q, %v", msg, err)
+	}
+	if content, err := cb.Read(); err != nil || content != "" {
+		t.Errorf("the clipboard holds %q, %v", content, err)
+	}
+}
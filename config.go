@@ -0,0 +1,147 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// config is the configuration file:
+//
+//	[defaults]
+//	no-clipboard = true
+//
+//	[profiles.aws-iam]
+//	command = "random"
+//	options = { length = 24, upper = true, digit = true, symbol = true }
+type config struct {
+	// Defaults are the values of the global flags not given on the
+	// command line nor in the environment, by flag name.
+	Defaults map[string]interface{} `toml:"defaults"`
+	// Profiles are the named profiles of -profile.
+	Profiles map[string]configProfile `toml:"profiles"`
+}
+
+// configProfile is a subcommand with its options.
+type configProfile struct {
+	// Command is the subcommand, e.g. "random".
+	Command string `toml:"command"`
+	// Options are the options of the subcommand, by flag name.
+	Options map[string]interface{} `toml:"options"`
+}
+
+// configPath returns the path of the configuration file: path if not
+// empty, else $PWGENIE_CONFIG, else pwgenie/config.toml in the user
+// configuration directory, e.g. $XDG_CONFIG_HOME on Linux. explicit
+// reports whether the file was asked for and must exist.
+func configPath(path string) (string, bool) {
+	if path != "" {
+		return path, true
+	}
+	if path = os.Getenv("PWGENIE_CONFIG"); path != "" {
+		return path, true
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", false
+	}
+	return filepath.Join(dir, "pwgenie", "config.toml"), false
+}
+
+// loadConfig reads the configuration file at path. A missing file is an
+// empty configuration unless explicit is set.
+func loadConfig(path string, explicit bool) (*config, error) {
+	var cfg config
+	if path == "" {
+		return &cfg, nil
+	}
+
+	md, err := toml.DecodeFile(path, &cfg)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) && !explicit {
+			return &cfg, nil
+		}
+		return nil, fmt.Errorf("config %s: %w", path, err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("config %s: unknown key %s", path, undecoded[0])
+	}
+	for name, p := range cfg.Profiles {
+		if p.Command == "" {
+			return nil, fmt.Errorf("config %s: profile %s has no command", path, name)
+		}
+	}
+	return &cfg, nil
+}
+
+// applyDefaults sets the flags not given on the command line from the
+// environment, then from the configuration. The environment variable of a
+// flag is its name in upper case, prefixed with PWGENIE_, e.g.
+// PWGENIE_NO_CLIPBOARD for -no-clipboard.
+func applyDefaults(flags *flag.FlagSet, cfg *config) error {
+	for name := range cfg.Defaults {
+		if flags.Lookup(name) == nil {
+			return fmt.Errorf("config: unknown flag %q in defaults", name)
+		}
+	}
+
+	set := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	var err error
+	flags.VisitAll(func(f *flag.Flag) {
+		if set[f.Name] || err != nil {
+			return
+		}
+		env := "PWGENIE_" + strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))
+		if v, ok := os.LookupEnv(env); ok {
+			if e := flags.Set(f.Name, v); e != nil {
+				err = fmt.Errorf("invalid %s %q: %w", env, v, e)
+			}
+			return
+		}
+		if v, ok := cfg.Defaults[f.Name]; ok {
+			if e := flags.Set(f.Name, fmt.Sprint(v)); e != nil {
+				err = fmt.Errorf("config: invalid value %v of %s: %w", v, f.Name, e)
+			}
+		}
+	})
+	return err
+}
+
+// args returns the command line of the profile: the subcommand and its
+// options, followed by extra arguments, which override the options.
+func (p configProfile) args(extra []string) []string {
+	names := make([]string, 0, len(p.Options))
+	for name := range p.Options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	args := []string{p.Command}
+	for _, name := range names {
+		args = append(args, fmt.Sprintf("-%s=%v", name, p.Options[name]))
+	}
+	return append(args, extra...)
+}
@@ -0,0 +1,212 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	tests := []struct {
+		name, content string
+		explicit      bool
+		err           string
+		profiles      int
+	}{
+		{
+			name: "valid",
+			content: `
+[defaults]
+no-clipboard = true
+
+[profiles.wifi]
+command = "human"
+options = { words = 3, sep = "-" }
+
+[profiles.pin]
+command = "pin"
+`,
+			profiles: 2,
+		},
+		{name: "empty", content: "", profiles: 0},
+		{name: "missing", profiles: 0},
+		{name: "missing", explicit: true, err: "no such file"},
+		{name: "unknown-key", content: "[default]\nno-clipboard = true\n", err: "unknown key default"},
+		{name: "unknown-profile-key", content: "[profiles.wifi]\ncommand = \"human\"\nwords = 3\n", err: "unknown key profiles.wifi.words"},
+		{name: "no-command", content: "[profiles.wifi]\noptions = { words = 3 }\n", err: "profile wifi has no command"},
+		{name: "invalid", content: "[defaults\n", err: "config "},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name+".toml")
+		if tt.content != "" || tt.name != "missing" {
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		cfg, err := loadConfig(path, tt.explicit)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: got error %v, want %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if len(cfg.Profiles) != tt.profiles {
+			t.Errorf("%s: got %d profiles, want %d", tt.name, len(cfg.Profiles), tt.profiles)
+		}
+	}
+}
+
+// testFlags returns a flag set with the flags of the tests of
+// applyDefaults, parsed from args.
+func testFlags(t *testing.T, args ...string) *flag.FlagSet {
+	t.Helper()
+	flags := flag.NewFlagSet("pwgenie", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.Bool("no-clipboard", false, "")
+	flags.Int("count", 1, "")
+	flags.String("format", "text", "")
+	if err := flags.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return flags
+}
+
+// The tests of applyDefaults set the environment, so they do not run in
+// parallel.
+func TestApplyDefaults(t *testing.T) {
+	cfg := &config{Defaults: map[string]interface{}{
+		"no-clipboard": true,
+		"count":        int64(3),
+		"format":       "json",
+	}}
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		want map[string]string
+	}{
+		{
+			name: "config",
+			want: map[string]string{"no-clipboard": "true", "count": "3", "format": "json"},
+		},
+		{
+			name: "environment",
+			env:  map[string]string{"PWGENIE_COUNT": "5", "PWGENIE_NO_CLIPBOARD": "false"},
+			want: map[string]string{"no-clipboard": "false", "count": "5", "format": "json"},
+		},
+		{
+			name: "flags",
+			args: []string{"-count=7", "-format=csv"},
+			env:  map[string]string{"PWGENIE_COUNT": "5", "PWGENIE_FORMAT": "yaml"},
+			want: map[string]string{"no-clipboard": "true", "count": "7", "format": "csv"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"PWGENIE_NO_CLIPBOARD", "PWGENIE_COUNT", "PWGENIE_FORMAT"} {
+				t.Setenv(name, "")
+				os.Unsetenv(name)
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			flags := testFlags(t, tt.args...)
+			if err := applyDefaults(flags, cfg); err != nil {
+				t.Fatal(err)
+			}
+			got := make(map[string]string)
+			flags.VisitAll(func(f *flag.Flag) { got[f.Name] = f.Value.String() })
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		t.Setenv("PWGENIE_COUNT", "many")
+		if err := applyDefaults(testFlags(t), &config{}); err == nil || !strings.Contains(err.Error(), "PWGENIE_COUNT") {
+			t.Errorf("invalid environment: got error %v", err)
+		}
+		os.Unsetenv("PWGENIE_COUNT")
+
+		for name, v := range map[string]interface{}{"length": 12, "count": "many"} {
+			cfg := &config{Defaults: map[string]interface{}{name: v}}
+			if err := applyDefaults(testFlags(t), cfg); err == nil || !strings.Contains(err.Error(), name) {
+				t.Errorf("%s = %v: got error %v", name, v, err)
+			}
+		}
+	})
+}
+
+func TestConfigProfileArgs(t *testing.T) {
+	t.Parallel()
+
+	p := configProfile{
+		Command: "random",
+		Options: map[string]interface{}{"length": int64(24), "upper": true, "symbols": "!@#"},
+	}
+	got := p.args([]string{"-length=32", "-count=2"})
+	want := []string{"random", "-length=24", "-symbols=!@#", "-upper=true", "-length=32", "-count=2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestConfigProfileProfile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		profile configProfile
+		err     string
+	}{
+		{configProfile{Command: "human", Options: map[string]interface{}{"words": int64(4), "sep": "-", "cap": true}}, ""},
+		{configProfile{Command: "random", Options: map[string]interface{}{"length": int64(16), "exclude-ambiguous": true}}, ""},
+		{configProfile{Command: "pin", Options: map[string]interface{}{"length": int64(6)}}, ""},
+		{configProfile{Command: "check"}, "no profile equivalent"},
+		{configProfile{Command: "random", Options: map[string]interface{}{"bits": 80.0}}, "-bits"},
+		{configProfile{Command: "human", Options: map[string]interface{}{"words": "four"}}, "words"},
+	}
+	for _, tt := range tests {
+		prof, err := tt.profile.profile()
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%+v: got error %v, want %q", tt.profile, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%+v: %v", tt.profile, err)
+			continue
+		}
+		if prof.Mode != tt.profile.Command {
+			t.Errorf("%+v: got mode %s", tt.profile, prof.Mode)
+		}
+	}
+}
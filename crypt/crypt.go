@@ -0,0 +1,218 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package crypt hashes passwords in the modular crypt formats read by
+// /etc/shadow, htpasswd, cloud-init and Passlib, so that accounts can be
+// provisioned without the plaintext password:
+//
+//	hash, err := crypt.Hash(crypt.SHA512Crypt, "correct horse battery staple")
+//	// $6$rounds=656000$...
+package crypt
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// ErrUnknownAlgorithm is the error returned for an unsupported hashing
+// algorithm.
+var ErrUnknownAlgorithm = errors.New("unknown hashing algorithm")
+
+// Algorithm is a password hashing algorithm and its modular crypt format.
+type Algorithm string
+
+const (
+	// Bcrypt is bcrypt with cost 12: $2a$12$... Passwords longer than 72
+	// bytes are rejected.
+	Bcrypt Algorithm = "bcrypt"
+	// Argon2id is Argon2id with 64 MiB of memory, 3 passes and 4 lanes, in
+	// the PHC string format: $argon2id$v=19$m=65536,t=3,p=4$...
+	Argon2id Algorithm = "argon2id"
+	// Scrypt is scrypt with N=2^15, r=8 and p=1, in the $7$ format of
+	// libxcrypt.
+	Scrypt Algorithm = "scrypt"
+	// SHA512Crypt is the SHA-512 based crypt of glibc with 656000 rounds:
+	// $6$rounds=656000$...
+	SHA512Crypt Algorithm = "sha512crypt"
+	// PBKDF2SHA256 is PBKDF2-HMAC-SHA256 with 600000 iterations in the
+	// format of Passlib: $pbkdf2-sha256$600000$...
+	PBKDF2SHA256 Algorithm = "pbkdf2-sha256"
+)
+
+// Parameters of the algorithms, following the OWASP recommendations.
+const (
+	bcryptCost       = 12
+	argon2Memory     = 64 * 1024
+	argon2Time       = 3
+	argon2Threads    = 4
+	scryptLogN       = 15
+	scryptR          = 8
+	scryptP          = 1
+	sha512Rounds     = 656000
+	pbkdf2Iterations = 600000
+
+	// saltSize is the size in bytes of the binary salts.
+	saltSize = 16
+	// keySize is the size in bytes of the derived keys.
+	keySize = 32
+)
+
+// Algorithms returns the supported algorithms.
+func Algorithms() []Algorithm {
+	return []Algorithm{Bcrypt, Argon2id, Scrypt, SHA512Crypt, PBKDF2SHA256}
+}
+
+// ParseAlgorithm returns the algorithm with the given name.
+func ParseAlgorithm(name string) (Algorithm, error) {
+	for _, alg := range Algorithms() {
+		if string(alg) == name {
+			return alg, nil
+		}
+	}
+	return "", fmt.Errorf("%w %q", ErrUnknownAlgorithm, name)
+}
+
+// Hash returns the hash of password with the given algorithm and a random
+// salt, in the modular crypt format of the algorithm.
+func Hash(alg Algorithm, password string) (string, error) {
+	switch alg {
+	case Bcrypt:
+		if len(password) > 72 {
+			return "", errors.New("bcrypt cannot hash passwords longer than 72 bytes")
+		}
+		h, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost)
+		return string(h), err
+	case Argon2id:
+		salt, err := randomBytes(saltSize)
+		if err != nil {
+			return "", err
+		}
+		return argon2idHash(password, salt), nil
+	case Scrypt:
+		salt, err := randomSalt(22)
+		if err != nil {
+			return "", err
+		}
+		return scryptHash(password, salt, scryptLogN, scryptR, scryptP)
+	case SHA512Crypt:
+		salt, err := randomSalt(16)
+		if err != nil {
+			return "", err
+		}
+		return sha512Crypt(password, salt, sha512Rounds), nil
+	case PBKDF2SHA256:
+		salt, err := randomBytes(saltSize)
+		if err != nil {
+			return "", err
+		}
+		return pbkdf2Hash(password, salt, pbkdf2Iterations), nil
+	default:
+		return "", fmt.Errorf("%w %q", ErrUnknownAlgorithm, alg)
+	}
+}
+
+// argon2idHash returns the Argon2id hash of password in the PHC string
+// format.
+func argon2idHash(password string, salt []byte) string {
+	key := argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, keySize)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, argon2Memory, argon2Time, argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+}
+
+// scryptHash returns the scrypt hash of password in the $7$ format of
+// libxcrypt: the parameters and the salt characters, which are used as
+// is, followed by the key, encoded with the crypt alphabet.
+func scryptHash(password, salt string, logN, r, p int) (string, error) {
+	key, err := scrypt.Key([]byte(password), []byte(salt), 1<<logN, r, p, keySize)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString("$7$")
+	b.WriteByte(itoa64[logN])
+	encodeUint(&b, uint32(r), 30)
+	encodeUint(&b, uint32(p), 30)
+	b.WriteString(salt)
+	b.WriteByte('$')
+	encodeBytes(&b, key)
+	return b.String(), nil
+}
+
+// pbkdf2Hash returns the PBKDF2-HMAC-SHA256 hash of password in the
+// format of Passlib, whose base64 has '.' instead of '+' and no padding.
+func pbkdf2Hash(password string, salt []byte, iterations int) string {
+	key := pbkdf2.Key([]byte(password), salt, iterations, keySize, sha256.New)
+	ab64 := func(b []byte) string {
+		return strings.ReplaceAll(base64.RawStdEncoding.EncodeToString(b), "+", ".")
+	}
+	return fmt.Sprintf("$pbkdf2-sha256$%d$%s$%s", iterations, ab64(salt), ab64(key))
+}
+
+// itoa64 is the alphabet of the crypt formats.
+const itoa64 = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// encodeUint writes the low bits of v with the crypt alphabet, least
+// significant 6 bits first.
+func encodeUint(b *strings.Builder, v uint32, bits int) {
+	for ; bits > 0; bits -= 6 {
+		b.WriteByte(itoa64[v&0x3f])
+		v >>= 6
+	}
+}
+
+// encodeBytes writes src with the crypt alphabet, in little-endian groups
+// of 3 bytes as libxcrypt does.
+func encodeBytes(b *strings.Builder, src []byte) {
+	for i := 0; i < len(src); {
+		var v uint32
+		bits := 0
+		for ; bits < 24 && i < len(src); i++ {
+			v |= uint32(src[i]) << bits
+			bits += 8
+		}
+		encodeUint(b, v, bits)
+	}
+}
+
+// randomBytes returns n random bytes.
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// randomSalt returns a random salt of n characters of the crypt alphabet.
+func randomSalt(n int) (string, error) {
+	b, err := randomBytes(n)
+	if err != nil {
+		return "", err
+	}
+	for i := range b {
+		// 64 divides 256: every character is equally likely
+		b[i] = itoa64[b[i]&0x3f]
+	}
+	return string(b), nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crypt
+
+import (
+	"encoding/base64"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestSHA512Crypt(t *testing.T) {
+	t.Parallel()
+
+	// Test vectors of the specification.
+	tests := []struct {
+		password, salt string
+		rounds         int
+		want           string
+	}{
+		{"Hello world!", "saltstring", 5000, "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1"},
+		{"Hello world!", "saltstringsaltstring", 10000, "$6$rounds=10000$saltstringsaltst$OW1/O6BYHV6BcXZu8QVeXbDWra3Oeqh0sbHbbMCVNSnCM/UrjmM0Dp8vOuZeHBy/YTBmSK6H9qs/y3RnOaw5v."},
+		{"This is just a test", "toolongsaltstring", 5000, "$6$rounds=5000$toolongsaltstrin$lQ8jolhgVRVhY4b5pZKaysCLi0QBxGoNeKQzQ3glMhwllF7oGDZxUhx1yxdYcz/e1JSbq3y6JMxxl8audkUEm0"},
+	}
+	for _, tt := range tests {
+		got := sha512Crypt(tt.password, tt.salt, tt.rounds)
+		// The default rounds are only written when given explicitly.
+		want := strings.Replace(tt.want, "rounds=5000$", "", 1)
+		if got != want {
+			t.Errorf("sha512Crypt(%q, %q, %d) = %q, want %q", tt.password, tt.salt, tt.rounds, got, want)
+		}
+	}
+}
+
+func TestScryptHash(t *testing.T) {
+	t.Parallel()
+
+	// Test vector of libxcrypt, from the scrypt paper.
+	got, err := scryptHash("pleaseletmein", "SodiumChloride", 14, 8, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "$7$C6..../....SodiumChloride$kBGj9fHznVYFQMEn/qDCfrDevf9YDtcDdKvEqHJLV8D"
+	if got != want {
+		t.Errorf("scryptHash = %q, want %q", got, want)
+	}
+}
+
+func TestHash(t *testing.T) {
+	t.Parallel()
+
+	const password = "correct horse battery staple"
+	formats := map[Algorithm]*regexp.Regexp{
+		Bcrypt:       regexp.MustCompile(`^\$2a\$12\$[./A-Za-z0-9]{53}$`),
+		Argon2id:     regexp.MustCompile(`^\$argon2id\$v=19\$m=65536,t=3,p=4\$[+/A-Za-z0-9]{22}\$[+/A-Za-z0-9]{43}$`),
+		Scrypt:       regexp.MustCompile(`^\$7\$D6..../....[./A-Za-z0-9]{22}\$[./A-Za-z0-9]{43}$`),
+		SHA512Crypt:  regexp.MustCompile(`^\$6\$rounds=656000\$[./A-Za-z0-9]{16}\$[./A-Za-z0-9]{86}$`),
+		PBKDF2SHA256: regexp.MustCompile(`^\$pbkdf2-sha256\$600000\$[./A-Za-z0-9]{22}\$[./A-Za-z0-9]{43}$`),
+	}
+	for _, alg := range Algorithms() {
+		alg := alg
+		t.Run(string(alg), func(t *testing.T) {
+			t.Parallel()
+
+			h, err := Hash(alg, password)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !formats[alg].MatchString(h) {
+				t.Errorf("unexpected hash format %q", h)
+			}
+			if h2, _ := Hash(alg, password); h2 == h {
+				t.Error("hashes should have distinct salts")
+			}
+
+			// Hash again with the salt of the hash.
+			fields := strings.Split(h, "$")
+			var again string
+			switch alg {
+			case Bcrypt:
+				if err := bcrypt.CompareHashAndPassword([]byte(h), []byte(password)); err != nil {
+					t.Error(err)
+				}
+				return
+			case Argon2id:
+				salt, _ := base64.RawStdEncoding.DecodeString(fields[4])
+				again = argon2idHash(password, salt)
+			case Scrypt:
+				again, err = scryptHash(password, fields[2][11:], scryptLogN, scryptR, scryptP)
+			case SHA512Crypt:
+				again = sha512Crypt(password, fields[3], sha512Rounds)
+			case PBKDF2SHA256:
+				salt, _ := base64.RawStdEncoding.DecodeString(strings.ReplaceAll(fields[3], ".", "+"))
+				again = pbkdf2Hash(password, salt, pbkdf2Iterations)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if again != h {
+				t.Errorf("hash %q does not verify, got %q", h, again)
+			}
+		})
+	}
+
+	if _, err := Hash(Bcrypt, strings.Repeat("a", 73)); err == nil {
+		t.Error("bcrypt should reject passwords longer than 72 bytes")
+	}
+	if _, err := ParseAlgorithm("md5crypt"); !errors.Is(err, ErrUnknownAlgorithm) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
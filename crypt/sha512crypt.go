@@ -0,0 +1,129 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crypt
+
+import (
+	"crypto/sha512"
+	"strconv"
+	"strings"
+)
+
+// sha512DefaultRounds is the number of rounds of a $6$ hash without a
+// rounds= parameter.
+const sha512DefaultRounds = 5000
+
+// sha512Order is the order in which the bytes of the final digest are
+// encoded, 3 at a time, most significant first.
+var sha512Order = [...]int{
+	0, 21, 42, 22, 43, 1, 44, 2, 23, 3, 24, 45, 25, 46, 4,
+	47, 5, 26, 6, 27, 48, 28, 49, 7, 50, 8, 29, 9, 30, 51,
+	31, 52, 10, 53, 11, 32, 12, 33, 54, 34, 55, 13, 56, 14, 35,
+	15, 36, 57, 37, 58, 16, 59, 17, 38, 18, 39, 60, 40, 61, 19,
+	62, 20, 41,
+}
+
+// sha512Crypt returns the SHA-512 based crypt of password, as specified
+// by Ulrich Drepper <https://www.akkadia.org/drepper/SHA-crypt.txt>.
+// salt is truncated to 16 characters.
+func sha512Crypt(password, salt string, rounds int) string {
+	if len(salt) > 16 {
+		salt = salt[:16]
+	}
+	p, s := []byte(password), []byte(salt)
+
+	// Digest B: password, salt, password
+	h := sha512.New()
+	h.Write(p)
+	h.Write(s)
+	h.Write(p)
+	b := h.Sum(nil)
+
+	// Digest A: password, salt, B for the length of the password, then
+	// B or the password for every bit of the length of the password
+	h.Reset()
+	h.Write(p)
+	h.Write(s)
+	h.Write(repeat(b, len(p)))
+	for n := len(p); n > 0; n >>= 1 {
+		if n&1 != 0 {
+			h.Write(b)
+		} else {
+			h.Write(p)
+		}
+	}
+	a := h.Sum(nil)
+
+	// Sequence P: the digest of the password repeated once per byte
+	h.Reset()
+	for range p {
+		h.Write(p)
+	}
+	pSeq := repeat(h.Sum(nil), len(p))
+
+	// Sequence S: the digest of the salt repeated 16 + a[0] times
+	h.Reset()
+	for i := 0; i < 16+int(a[0]); i++ {
+		h.Write(s)
+	}
+	sSeq := repeat(h.Sum(nil), len(s))
+
+	for i := 0; i < rounds; i++ {
+		h.Reset()
+		if i&1 != 0 {
+			h.Write(pSeq)
+		} else {
+			h.Write(a)
+		}
+		if i%3 != 0 {
+			h.Write(sSeq)
+		}
+		if i%7 != 0 {
+			h.Write(pSeq)
+		}
+		if i&1 != 0 {
+			h.Write(a)
+		} else {
+			h.Write(pSeq)
+		}
+		a = h.Sum(a[:0])
+	}
+
+	var out strings.Builder
+	out.WriteString("$6$")
+	if rounds != sha512DefaultRounds {
+		out.WriteString("rounds=" + strconv.Itoa(rounds) + "$")
+	}
+	out.WriteString(salt)
+	out.WriteByte('$')
+	for i := 0; i < len(sha512Order); i += 3 {
+		v := uint32(a[sha512Order[i]])<<16 | uint32(a[sha512Order[i+1]])<<8 | uint32(a[sha512Order[i+2]])
+		encodeUint(&out, v, 24)
+	}
+	encodeUint(&out, uint32(a[63]), 12)
+	return out.String()
+}
+
+// repeat returns the first n bytes of b repeated as needed.
+func repeat(b []byte, n int) []byte {
+	result := make([]byte, 0, n)
+	for len(result) < n {
+		chunk := b
+		if len(chunk) > n-len(result) {
+			chunk = chunk[:n-len(result)]
+		}
+		result = append(result, chunk...)
+	}
+	return result
+}
@@ -0,0 +1,215 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ntk148v/pwgenie/generator"
+)
+
+// Limits of the daemon connections.
+const (
+	maxDaemonLine   = 4096
+	daemonIdleLimit = time.Minute
+)
+
+// daemon serves the line protocol of the daemon subcommand. Every request
+// is a line of space-separated words and gets a response starting with OK
+// or ERR:
+//
+//	PING                             OK
+//	PROFILES                         OK <name> <name>...
+//	GENERATE <profile> [n] [unique]  OK <n>, then one password per line
+//	QUIT                             OK, then the connection is closed
+//
+// An error is reported as "ERR <message>" and leaves the connection open.
+type daemon struct {
+	profiles map[string]profile
+}
+
+// serve accepts connections on l until it is closed.
+func (d *daemon) serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go d.handle(conn)
+	}
+}
+
+// handle serves the requests of a connection.
+func (d *daemon) handle(conn net.Conn) {
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 256), maxDaemonLine)
+	w := bufio.NewWriter(conn)
+	for {
+		_ = conn.SetDeadline(time.Now().Add(daemonIdleLimit))
+		if !scanner.Scan() {
+			if errors.Is(scanner.Err(), bufio.ErrTooLong) {
+				fmt.Fprintf(w, "ERR request longer than %d bytes\n", maxDaemonLine)
+				_ = w.Flush()
+			}
+			return
+		}
+
+		quit := d.respond(w, strings.Fields(scanner.Text()))
+		if err := w.Flush(); err != nil || quit {
+			return
+		}
+	}
+}
+
+// respond writes the response to a request to w, and reports whether the
+// connection must be closed.
+func (d *daemon) respond(w io.Writer, request []string) bool {
+	if len(request) == 0 {
+		fmt.Fprintln(w, "ERR empty request")
+		return false
+	}
+
+	switch strings.ToUpper(request[0]) {
+	case "PING":
+		fmt.Fprintln(w, "OK")
+	case "PROFILES":
+		fmt.Fprintln(w, "OK", strings.Join(profileNames(d.profiles), " "))
+	case "GENERATE":
+		passwords, err := d.generate(request[1:])
+		if err != nil {
+			fmt.Fprintln(w, "ERR", err)
+			return false
+		}
+		fmt.Fprintln(w, "OK", len(passwords))
+		for _, pass := range passwords {
+			fmt.Fprintln(w, pass)
+		}
+	case "QUIT":
+		fmt.Fprintln(w, "OK")
+		return true
+	default:
+		fmt.Fprintf(w, "ERR unknown command %q\n", request[0])
+	}
+	return false
+}
+
+// generate generates the passwords of a GENERATE request, whose arguments
+// are the profile name, optionally followed by the number of passwords and
+// the unique keyword.
+func (d *daemon) generate(args []string) ([]string, error) {
+	if len(args) == 0 || len(args) > 3 {
+		return nil, errors.New("usage: GENERATE <profile> [n] [unique]")
+	}
+	p, ok := d.profiles[args[0]]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %q", args[0])
+	}
+	gen, params, batch, err := p.generator()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, arg := range args[1:] {
+		if strings.EqualFold(arg, "unique") {
+			batch.Unique = true
+			continue
+		}
+		if batch.Count, err = strconv.Atoi(arg); err != nil {
+			return nil, fmt.Errorf("invalid number of passwords %q", arg)
+		}
+	}
+	if err := batch.validate(params); err != nil {
+		return nil, err
+	}
+
+	passwords, err := generator.GenerateBatch(rand.Reader, gen, generator.BatchOptions{
+		Count:   batch.Count,
+		Unique:  batch.Unique,
+		Workers: 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, pass := range passwords {
+		if strings.ContainsAny(pass, "\r\n") {
+			return nil, fmt.Errorf("profile %q generates passwords spanning several lines", args[0])
+		}
+	}
+	return passwords, nil
+}
+
+// listenSocket listens on the Unix domain socket at path, which only the
+// owner and the members of group, if not empty, may connect to with the
+// given permissions. A stale socket left by a previous daemon is removed.
+func listenSocket(path string, mode os.FileMode, group string) (net.Listener, error) {
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s is in use by another daemon", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	l, err := listenUnix(path)
+	if err != nil {
+		return nil, err
+	}
+	if group != "" {
+		if err := chownGroup(path, group); err != nil {
+			l.Close()
+			return nil, err
+		}
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// chownGroup changes the group of path to the given group name or ID.
+func chownGroup(path, group string) error {
+	g, err := user.LookupGroup(group)
+	if err != nil {
+		if g, err = user.LookupGroupId(group); err != nil {
+			return fmt.Errorf("unknown group %q", group)
+		}
+	}
+	gid, err := strconv.Atoi(g.Gid)
+	if err != nil {
+		return fmt.Errorf("invalid group ID %q: %w", g.Gid, err)
+	}
+	return os.Chown(path, -1, gid)
+}
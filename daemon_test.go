@@ -0,0 +1,166 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestDaemonProtocol(t *testing.T) {
+	t.Parallel()
+
+	client, conn := net.Pipe()
+	defer client.Close()
+	done := make(chan struct{})
+	go func() {
+		(&daemon{profiles: builtinProfiles}).handle(conn)
+		close(done)
+	}()
+
+	r := bufio.NewReader(client)
+	// request sends a request and returns the first line of the response.
+	request := func(line string) string {
+		t.Helper()
+		if _, err := fmt.Fprintln(client, line); err != nil {
+			t.Fatal(err)
+		}
+		resp, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.TrimSuffix(resp, "\n")
+	}
+
+	tests := []struct {
+		request, response string
+	}{
+		{"PING", "OK"},
+		{"ping", "OK"},
+		{"PROFILES", "OK api-key human passphrase password pin random"},
+		{"", "ERR empty request"},
+		{"HELLO", `ERR unknown command "HELLO"`},
+		{"GENERATE", "ERR usage: GENERATE <profile> [n] [unique]"},
+		{"GENERATE unknown", `ERR unknown profile "unknown"`},
+		{"GENERATE pin x", `ERR invalid number of passwords "x"`},
+		{"GENERATE pin 0 unique", "OK 1"},
+		{"GENERATE pin 1001", "ERR invalid request: count must be between 1 and 1000"},
+	}
+	for _, tt := range tests {
+		if got := request(tt.request); got != tt.response {
+			t.Errorf("%q: got %q, want %q", tt.request, got, tt.response)
+		}
+		if strings.HasPrefix(tt.response, "OK 1") {
+			_, _ = r.ReadString('\n')
+		}
+	}
+
+	if got := request("GENERATE password 3 unique"); got != "OK 3" {
+		t.Fatalf("got %q", got)
+	}
+	seen := make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		pass, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		pass = strings.TrimSuffix(pass, "\n")
+		if len(pass) != 20 || seen[pass] {
+			t.Errorf("unexpected password %q", pass)
+		}
+		seen[pass] = true
+	}
+
+	if got := request("QUIT"); got != "OK" {
+		t.Errorf("QUIT: got %q", got)
+	}
+	<-done
+	if _, err := r.ReadString('\n'); err != io.EOF {
+		t.Errorf("the connection should be closed after QUIT, got %v", err)
+	}
+}
+
+func TestDaemonProfiles(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config{Profiles: map[string]configProfile{
+		"wifi":     {Command: "human", Options: map[string]interface{}{"words": int64(3), "sep": "-"}},
+		"password": {Command: "random", Options: map[string]interface{}{"length": int64(12), "digit": true}},
+		"bits":     {Command: "random", Options: map[string]interface{}{"bits": 80.0}},
+		"invalid":  {Command: "pin", Options: map[string]interface{}{"length": "six"}},
+		"check":    {Command: "check"},
+	}}
+	var warnings strings.Builder
+	d := &daemon{profiles: daemonProfiles(cfg, false, &warnings)}
+
+	for _, name := range []string{"bits", "invalid", "check"} {
+		if _, ok := d.profiles[name]; ok {
+			t.Errorf("profile %s should not be served", name)
+		}
+		if !strings.Contains(warnings.String(), "profile "+name+" ") {
+			t.Errorf("missing warning for profile %s in %q", name, warnings.String())
+		}
+	}
+
+	passwords, err := d.generate([]string{"wifi", "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pass := range passwords {
+		if strings.Count(pass, "-") != 2 {
+			t.Errorf("wifi: unexpected password %q", pass)
+		}
+	}
+
+	// The configuration replaces the built-in profile.
+	passwords, err = d.generate([]string{"password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(passwords) != 1 || len(passwords[0]) != 12 || !strings.ContainsAny(passwords[0], "0123456789") {
+		t.Errorf("password: unexpected passwords %q", passwords)
+	}
+	if _, ok := d.profiles["passphrase"]; !ok {
+		t.Error("the other built-in profiles should be served")
+	}
+}
+
+func TestDaemonProfilesAllowRepeat(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config{Profiles: map[string]configProfile{
+		"wifi": {Command: "human", Options: map[string]interface{}{"words": int64(3)}},
+	}}
+	for _, allowRepeat := range []bool{false, true} {
+		var warnings strings.Builder
+		profiles := daemonProfiles(cfg, allowRepeat, &warnings)
+		if warnings.Len() > 0 {
+			t.Errorf("allowRepeat=%t: unexpected warnings %q", allowRepeat, warnings.String())
+		}
+		for _, name := range []string{"wifi", "passphrase", "password", "pin"} {
+			_, params, _, err := profiles[name].generator()
+			if err != nil {
+				t.Fatalf("allowRepeat=%t: %s: %v", allowRepeat, name, err)
+			}
+			if params.AllowRepeat != allowRepeat {
+				t.Errorf("allowRepeat=%t: %s: got allowRepeat %t", allowRepeat, name, params.AllowRepeat)
+			}
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build unix
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestListenSocket(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "pwgenie.sock")
+
+	l, err := listenSocket(path, 0o660, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode()&os.ModeSocket == 0 || fi.Mode().Perm() != 0o660 {
+		t.Errorf("got mode %v, want a socket with permissions 0660", fi.Mode())
+	}
+
+	go func() { _ = (&daemon{profiles: builtinProfiles}).serve(l) }()
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintln(conn, "PING")
+	if resp, err := bufio.NewReader(conn).ReadString('\n'); err != nil || resp != "OK\n" {
+		t.Errorf("PING: got %q, %v", resp, err)
+	}
+	conn.Close()
+
+	if _, err := listenSocket(path, 0o600, ""); err == nil {
+		t.Error("a socket in use should not be replaced")
+	}
+
+	// Closing the listener removes the socket; a stale socket is replaced.
+	l.Close()
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+	l, err = listenSocket(path, 0o600, "")
+	if err != nil {
+		t.Fatalf("stale socket: %v", err)
+	}
+	l.Close()
+
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := listenSocket(file, 0o600, ""); err == nil {
+		t.Error("a file which is not a socket should not be replaced")
+	}
+	if _, err := listenSocket(filepath.Join(dir, "group.sock"), 0o660, "no-such-group-pwgenie"); err == nil {
+		t.Error("an unknown group should fail")
+	}
+}
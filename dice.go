@@ -0,0 +1,77 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/ntk148v/pwgenie/generator"
+)
+
+// diceReader returns the reader the dice rolls are read from. On a
+// terminal, it prompts for the rolls of each word, validates them and
+// reports when enough rolls have been entered; otherwise the rolls are read
+// from stdin as they are.
+func diceReader(opts generator.HumanOptions) (io.Reader, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return os.Stdin, nil
+	}
+
+	list := opts.Wordlist
+	if list == nil {
+		list = generator.WordlistEFFLarge
+	}
+	digits := list.DiceDigits()
+	if digits == 0 {
+		return nil, fmt.Errorf("%w: %s has %d words, which is not a power of 6", generator.ErrInvalidWordlist, list.Name, len(list.Words))
+	}
+
+	var (
+		rolls   []string
+		seen    = make(map[string]bool)
+		scanner = bufio.NewScanner(os.Stdin)
+	)
+	for len(rolls) < opts.Words {
+		fmt.Fprintf(os.Stderr, "Roll %d dice for word %d of %d: ", digits, len(rolls)+1, opts.Words)
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%w: not enough rolls, got %d of %d words", generator.ErrInvalidRoll, len(rolls), opts.Words)
+		}
+
+		roll := strings.Join(strings.Fields(scanner.Text()), "")
+		word, err := list.Roll(roll)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			continue
+		}
+		if !opts.AllowRepeat && seen[word] {
+			fmt.Fprintln(os.Stderr, "This word is already used, roll again.")
+			continue
+		}
+		seen[word] = true
+		rolls = append(rolls, roll)
+	}
+	fmt.Fprintf(os.Stderr, "Enough rolls for %d words.\n", opts.Words)
+
+	return strings.NewReader(strings.Join(rolls, " ")), nil
+}
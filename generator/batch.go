@@ -0,0 +1,135 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"runtime"
+	"sync"
+)
+
+// ErrBatchNotUnique is the error returned when a unique batch cannot be
+// generated because the generator has too few distinct passwords.
+var ErrBatchNotUnique = errors.New("not enough distinct passwords for a unique batch")
+
+// maxDuplicates is the number of duplicate passwords in a row after which
+// GenerateBatch gives up on a unique batch.
+const maxDuplicates = 1000
+
+// BatchOptions configures GenerateBatch.
+type BatchOptions struct {
+	// Count is the number of passwords to generate.
+	Count int
+	// Unique guarantees that no two passwords of the batch are identical.
+	Unique bool
+	// Workers is the number of passwords generated in parallel. Zero
+	// means runtime.GOMAXPROCS(0).
+	Workers int
+}
+
+// GenerateBatch generates opts.Count passwords with g, reading randomness
+// from r. r is shared by the workers under a lock, so it needs not be safe
+// for concurrent use. With several workers, the passwords are in no
+// particular order.
+func GenerateBatch(r io.Reader, g Generator, opts BatchOptions) ([]string, error) {
+	if opts.Count <= 0 {
+		return nil, nil
+	}
+
+	if opts.Unique {
+		bits, err := g.Entropy()
+		if err != nil {
+			return nil, err
+		}
+		if math.Exp2(bits) < float64(opts.Count)-0.5 {
+			return nil, fmt.Errorf("%w: %d passwords requested but only %.0f exist", ErrBatchNotUnique, opts.Count, math.Exp2(bits))
+		}
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > opts.Count {
+		workers = opts.Count
+	}
+
+	var (
+		wg         sync.WaitGroup
+		mu         sync.Mutex
+		result     = make([]string, 0, opts.Count)
+		seen       = make(map[string]bool)
+		duplicates int
+		firstErr   error
+		src        = &lockedReader{r: r}
+	)
+	// done reports whether the batch is complete or failed.
+	done := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return firstErr != nil || len(result) >= opts.Count
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !done() {
+				pass, err := g.Generate(src)
+
+				mu.Lock()
+				switch {
+				case firstErr != nil || len(result) >= opts.Count:
+				case err != nil:
+					firstErr = err
+				case opts.Unique && seen[pass]:
+					duplicates++
+					if duplicates >= maxDuplicates {
+						firstErr = fmt.Errorf("%w: %d duplicate passwords in a row after %d passwords", ErrBatchNotUnique, duplicates, len(result))
+					}
+				default:
+					if opts.Unique {
+						seen[pass] = true
+					}
+					duplicates = 0
+					result = append(result, pass)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return result, nil
+}
+
+// lockedReader serializes the reads of a reader shared by goroutines.
+type lockedReader struct {
+	mu sync.Mutex
+	r  io.Reader
+}
+
+// Read implements io.Reader.
+func (l *lockedReader) Read(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Read(p)
+}
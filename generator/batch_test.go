@@ -0,0 +1,75 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGenerateBatch(t *testing.T) {
+	t.Parallel()
+
+	t.Run("count", func(t *testing.T) {
+		t.Parallel()
+
+		res, err := GenerateBatch(r, NewPIN(PINOptions{Length: 2, AllowRepeat: true}), BatchOptions{Count: N, Workers: 4})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res) != N {
+			t.Errorf("got %d passwords, want %d", len(res), N)
+		}
+	})
+
+	t.Run("unique", func(t *testing.T) {
+		t.Parallel()
+
+		// Every one of the 10 one-digit PIN codes.
+		res, err := GenerateBatch(r, NewPIN(PINOptions{Length: 1}), BatchOptions{Count: 10, Unique: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res) != 10 || hasDuplicate(res) {
+			t.Errorf("%q should be 10 distinct PIN codes", res)
+		}
+
+		res, err = GenerateBatch(r, NewHuman(HumanOptions{Words: 1}), BatchOptions{Count: N, Unique: true, Workers: 8})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res) != N || hasDuplicate(res) {
+			t.Errorf("got %d passwords, some of them repeated", len(res))
+		}
+	})
+
+	t.Run("not_unique", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := GenerateBatch(r, NewPIN(PINOptions{Length: 1}), BatchOptions{Count: 11, Unique: true})
+		if !errors.Is(err, ErrBatchNotUnique) {
+			t.Errorf("%v should be %q", err, ErrBatchNotUnique)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := GenerateBatch(r, NewPIN(PINOptions{Length: 11}), BatchOptions{Count: 3})
+		if !errors.Is(err, ErrTooManyCharacters) {
+			t.Errorf("%v should be %q", err, ErrTooManyCharacters)
+		}
+	})
+}
@@ -0,0 +1,76 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"embed"
+	"strings"
+	"sync"
+)
+
+// The blocklist is made of the 7,141 most common passwords of zxcvbn
+// <https://github.com/dropbox/zxcvbn> (MIT License), most frequent first,
+// and of notorious PIN codes: the most common ones found by DataGenetics,
+// repeated digits, sequences, keypad patterns, years and dates. It is
+// small enough to embed, but far from a breach corpus of millions of
+// passwords: Have I Been Pwned lists are looked up by the breach package.
+//
+//go:embed blocklist/*.txt
+var blocklistFS embed.FS
+
+var (
+	blocklistOnce   sync.Once
+	commonPasswords []string
+	blocklist       map[string]bool
+)
+
+// loadBlocklist loads the embedded lists.
+func loadBlocklist() {
+	blocklistOnce.Do(func() {
+		commonPasswords = readList("blocklist/passwords.txt")
+		pins := readList("blocklist/pins.txt")
+
+		blocklist = make(map[string]bool, len(commonPasswords)+len(pins))
+		for _, list := range [][]string{commonPasswords, pins} {
+			for _, w := range list {
+				blocklist[w] = true
+			}
+		}
+	})
+}
+
+// readList reads an embedded newline-separated list.
+func readList(name string) []string {
+	b, err := blocklistFS.ReadFile(name)
+	if err != nil {
+		panic(err)
+	}
+	return strings.Split(strings.TrimSpace(string(b)), "\n")
+}
+
+// CommonPasswords returns the common passwords of the blocklist, in
+// lowercase and most frequent first.
+func CommonPasswords() []string {
+	loadBlocklist()
+	return commonPasswords
+}
+
+// Blocked reports whether pass is a common password or a notorious PIN
+// code of the embedded blocklist, regardless of case. NIST SP 800-63B
+// asks for passwords to be screened against such a list.
+func Blocked(pass string) bool {
+	loadBlocklist()
+	return blocklist[strings.ToLower(pass)]
+}
@@ -0,0 +1,76 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"testing"
+)
+
+func TestBlocked(t *testing.T) {
+	t.Parallel()
+
+	t.Run("list", func(t *testing.T) {
+		t.Parallel()
+
+		for _, pass := range []string{"123456", "000000", "112233", "1234", "2580", "1987", "PASSWORD", "qwerty"} {
+			if !Blocked(pass) {
+				t.Errorf("%q should be blocked", pass)
+			}
+		}
+		for _, pass := range []string{"", "7$Kq!vP9", "830571"} {
+			if Blocked(pass) {
+				t.Errorf("%q should not be blocked", pass)
+			}
+		}
+		if CommonPasswords()[0] != "password" {
+			t.Errorf("%q should be the most common password", CommonPasswords()[0])
+		}
+	})
+
+	t.Run("pin", func(t *testing.T) {
+		t.Parallel()
+
+		// 24 PIN codes, 0123, 3210 and a few dates among them.
+		g := NewPIN(PINOptions{Length: 4, Exclude: "456789"})
+		for i := 0; i < N; i++ {
+			res, err := g.Generate(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if Blocked(res) {
+				t.Fatalf("%q should have been drawn again", res)
+			}
+		}
+	})
+
+	t.Run("random", func(t *testing.T) {
+		t.Parallel()
+
+		g := NewRandomWithPolicy(Policy{
+			MinLength:   4,
+			Classes:     []CharClass{{Name: "digit", Chars: "0123"}},
+			AllowRepeat: true,
+		})
+		for i := 0; i < N; i++ {
+			res, err := g.Generate(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if Blocked(res) {
+				t.Fatalf("%q should have been drawn again", res)
+			}
+		}
+	})
+}
@@ -0,0 +1,140 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// LessPassSymbols is the list of symbols of LessPass, every printable
+// ASCII character which is neither a letter nor a digit.
+const LessPassSymbols = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+// Parameters of the LessPass v2 key derivation.
+const (
+	lessPassIterations = 100000
+	lessPassKeyLength  = 32
+)
+
+// ErrInvalidDerivation is the error returned when a password cannot be
+// derived with the given options.
+var ErrInvalidDerivation = errors.New("invalid derivation options")
+
+// LessPassOptions configures DeriveLessPass. The zero values of Length and
+// Counter are the defaults of LessPass, 16 and 1.
+type LessPassOptions struct {
+	// Site is the site the password is for, e.g. "example.org".
+	Site string
+	// Login is the login on the site.
+	Login string
+	// Counter is incremented to change the password of a site.
+	Counter int
+	// Length is the number of characters in the derived password.
+	Length int
+	// Lower, Upper, Digits and Symbols enable the character classes of
+	// the password, which gets at least one character of each.
+	Lower   bool
+	Upper   bool
+	Digits  bool
+	Symbols bool
+}
+
+// classes returns the enabled character classes in LessPass order.
+func (o LessPassOptions) classes() []CharClass {
+	var classes []CharClass
+	for _, c := range []struct {
+		on bool
+		CharClass
+	}{
+		{o.Lower, CharClass{Name: "lower", Chars: LowerLetters, Min: 1}},
+		{o.Upper, CharClass{Name: "upper", Chars: UpperLetters, Min: 1}},
+		{o.Digits, CharClass{Name: "digit", Chars: Digits, Min: 1}},
+		{o.Symbols, CharClass{Name: "symbol", Chars: LessPassSymbols, Min: 1}},
+	} {
+		if c.on {
+			classes = append(classes, c.CharClass)
+		}
+	}
+	return classes
+}
+
+// DeriveLessPass derives the password of a site from a master password
+// exactly like LessPass v2: the same inputs always give the same password.
+//
+// The entropy is PBKDF2-SHA256 of the master password salted with the
+// site, the login and the counter in hexadecimal. It is consumed as a big
+// number to pick the characters from the enabled classes, then one
+// character of each class, inserted at pseudo-random positions.
+func DeriveLessPass(master string, opts LessPassOptions) (string, error) {
+	if opts.Length == 0 {
+		opts.Length = 16
+	}
+	if opts.Counter == 0 {
+		opts.Counter = 1
+	}
+	classes := opts.classes()
+	if len(classes) == 0 {
+		return "", fmt.Errorf("%w: no character class enabled", ErrInvalidDerivation)
+	}
+	if opts.Length < len(classes) {
+		return "", fmt.Errorf("%w: length %d is shorter than the %d enabled classes", ErrInvalidDerivation, opts.Length, len(classes))
+	}
+	if opts.Counter < 0 {
+		return "", fmt.Errorf("%w: negative counter %d", ErrInvalidDerivation, opts.Counter)
+	}
+
+	salt := opts.Site + opts.Login + strconv.FormatInt(int64(opts.Counter), 16)
+	key := pbkdf2.Key([]byte(master), []byte(salt), lessPassIterations, lessPassKeyLength, sha256.New)
+	entropy := new(big.Int).SetBytes(key)
+
+	var all string
+	for _, c := range classes {
+		all += c.Chars
+	}
+	password := consumeEntropy(entropy, all, opts.Length-len(classes))
+
+	perClass := make([]byte, 0, len(classes))
+	for _, c := range classes {
+		perClass = append(perClass, consumeEntropy(entropy, c.Chars, 1)...)
+	}
+	for _, c := range perClass {
+		i := divmod(entropy, len(password))
+		password = append(password[:i], append([]byte{c}, password[i:]...)...)
+	}
+
+	return string(password), nil
+}
+
+// consumeEntropy picks n characters of chars with the entropy, which is
+// divided by len(chars) for each of them.
+func consumeEntropy(entropy *big.Int, chars string, n int) []byte {
+	result := make([]byte, n)
+	for i := range result {
+		result[i] = chars[divmod(entropy, len(chars))]
+	}
+	return result
+}
+
+// divmod divides x by n in place and returns the remainder.
+func divmod(x *big.Int, n int) int {
+	_, m := x.DivMod(x, big.NewInt(int64(n)), new(big.Int))
+	return int(m.Int64())
+}
@@ -0,0 +1,92 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDeriveLessPass(t *testing.T) {
+	t.Parallel()
+
+	site := LessPassOptions{
+		Site:    "example.org",
+		Login:   "contact@example.org",
+		Lower:   true,
+		Upper:   true,
+		Digits:  true,
+		Symbols: true,
+	}
+
+	t.Run("lesspass", func(t *testing.T) {
+		t.Parallel()
+
+		// Test vectors of LessPass.
+		noSymbols := site
+		noSymbols.Length, noSymbols.Counter, noSymbols.Symbols = 14, 2, false
+		tests := []struct {
+			opts LessPassOptions
+			want string
+		}{
+			{site, "WHLpUL)e00[iHR+w"},
+			{noSymbols, "MBAsB7b1Prt8Sl"},
+			{LessPassOptions{Site: "example.org", Login: "contact@example.org", Length: 6, Counter: 3, Digits: true}, "117843"},
+		}
+		for _, tt := range tests {
+			res, err := DeriveLessPass("password", tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res != tt.want {
+				t.Errorf("%+v: got %q, want %q", tt.opts, res, tt.want)
+			}
+		}
+	})
+
+	t.Run("classes", func(t *testing.T) {
+		t.Parallel()
+
+		opts := site
+		opts.Length, opts.Symbols = 6, false
+		res, err := DeriveLessPass("password", opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res) != 6 || strings.ContainsAny(res, LessPassSymbols) {
+			t.Errorf("%q should be 6 letters and digits", res)
+		}
+		for _, chars := range []string{LowerLetters, UpperLetters, Digits} {
+			if !strings.ContainsAny(res, chars) {
+				t.Errorf("%q should contain one of %q", res, chars)
+			}
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		for _, opts := range []LessPassOptions{
+			{Site: "example.org"},
+			{Site: "example.org", Length: 1, Lower: true, Upper: true},
+			{Site: "example.org", Counter: -1, Lower: true},
+		} {
+			if _, err := DeriveLessPass("password", opts); !errors.Is(err, ErrInvalidDerivation) {
+				t.Errorf("%+v: %v should be %q", opts, err, ErrInvalidDerivation)
+			}
+		}
+	})
+}
@@ -0,0 +1,141 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidRoll is the error returned when dice rolls are not valid.
+var ErrInvalidRoll = errors.New("invalid dice roll")
+
+// DiceDigits returns the number of dice rolled to pick a word of the list
+// with the diceware numbering, e.g. 5 for a list of 7776 words. It returns
+// 0 when the size of the list is not a power of 6.
+func (w *Wordlist) DiceDigits() int {
+	n, digits := len(w.Words), 0
+	for n > 1 && n%6 == 0 {
+		n /= 6
+		digits++
+	}
+	if n != 1 {
+		return 0
+	}
+	return digits
+}
+
+// Roll returns the word numbered roll in the diceware numbering of the
+// list, e.g. "11111" for the first word of a list of 7776 words.
+func (w *Wordlist) Roll(roll string) (string, error) {
+	digits := w.DiceDigits()
+	if digits == 0 {
+		return "", fmt.Errorf("%w: %s has %d words, which is not a power of 6", ErrInvalidWordlist, w.Name, len(w.Words))
+	}
+	if len(roll) != digits {
+		return "", fmt.Errorf("%w: %q: %d dice are needed per word, got %d", ErrInvalidRoll, roll, digits, len(roll))
+	}
+
+	var index int
+	for _, d := range roll {
+		if d < '1' || d > '6' {
+			return "", fmt.Errorf("%w: %q: %q is not a die value between 1 and 6", ErrInvalidRoll, roll, d)
+		}
+		index = index*6 + int(d-'1')
+	}
+
+	return w.Words[index], nil
+}
+
+// Dice generates human-friendly memorable passwords from physical dice
+// rolls instead of a random source: entropy humans can audit.
+type Dice struct {
+	opts HumanOptions
+}
+
+// NewDice returns a Dice generator with the given options.
+func NewDice(opts HumanOptions) *Dice {
+	return &Dice{opts: opts}
+}
+
+// Generate implements Generator. r is not a random source but the dice
+// rolls: as many digits between 1 and 6 as the wordlist needs per word,
+// see Wordlist.DiceDigits. Whitespace between rolls is ignored. Unless
+// repeats are allowed, a roll giving a word already picked is skipped and
+// the next one is used. Rolls after the last word are ignored.
+func (g *Dice) Generate(r io.Reader) (string, error) {
+	list := g.opts.wordlist()
+	digits := list.DiceDigits()
+	if digits == 0 {
+		return "", fmt.Errorf("%w: %s has %d words, which is not a power of 6", ErrInvalidWordlist, list.Name, len(list.Words))
+	}
+	if g.opts.Words < 0 {
+		return "", fmt.Errorf("%w: %d words", ErrNegativeLength, g.opts.Words)
+	}
+	if !g.opts.AllowRepeat && g.opts.Words > len(list.Words) {
+		return "", ErrTooManyCharacters
+	}
+
+	var (
+		br    = bufio.NewReader(r)
+		roll  strings.Builder
+		words []string
+		seen  = make(map[string]bool)
+	)
+	for len(words) < g.opts.Words {
+		ch, _, err := br.ReadRune()
+		if err == io.EOF {
+			return "", fmt.Errorf("%w: not enough rolls, got %d of %d words", ErrInvalidRoll, len(words), g.opts.Words)
+		}
+		if err != nil {
+			return "", err
+		}
+		if unicode.IsSpace(ch) {
+			continue
+		}
+
+		roll.WriteRune(ch)
+		if ch < '1' || ch > '6' {
+			return "", fmt.Errorf("%w: %q is not a die value between 1 and 6", ErrInvalidRoll, ch)
+		}
+		if roll.Len() < digits {
+			continue
+		}
+
+		word, err := list.Roll(roll.String())
+		if err != nil {
+			return "", err
+		}
+		roll.Reset()
+
+		if !g.opts.AllowRepeat && seen[word] {
+			continue
+		}
+		seen[word] = true
+		words = append(words, word)
+	}
+
+	return formatWords(words, g.opts), nil
+}
+
+// Entropy implements Generator. It is the entropy of a Human generator
+// with the same options, assuming fair dice.
+func (g *Dice) Entropy() (float64, error) {
+	return NewHuman(g.opts).Entropy()
+}
@@ -0,0 +1,118 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWordlistRoll(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		list *Wordlist
+		roll string
+		want string
+	}{
+		{WordlistEFFLarge, "11111", "abacus"},
+		{WordlistEFFLarge, "11112", "abdomen"},
+		{WordlistEFFLarge, "66666", "zoom"},
+		{WordlistEFFShort1, "1111", "acid"},
+		{WordlistEFFShort1, "6666", "zoom"},
+		{WordlistEFFShort2, "1111", "aardvark"},
+		{WordlistDiceware, "11111", "a"},
+		{WordlistGerman, "1111", "aal"},
+	}
+	for _, tt := range tests {
+		got, err := tt.list.Roll(tt.roll)
+		if err != nil {
+			t.Errorf("%s %s: %s", tt.list.Name, tt.roll, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s %s: got %q, want %q", tt.list.Name, tt.roll, got, tt.want)
+		}
+	}
+
+	for _, roll := range []string{"1111", "111111", "11117", "1111a"} {
+		if _, err := WordlistEFFLarge.Roll(roll); !errors.Is(err, ErrInvalidRoll) {
+			t.Errorf("%q: %v should be %q", roll, err, ErrInvalidRoll)
+		}
+	}
+}
+
+func TestDice(t *testing.T) {
+	t.Parallel()
+
+	t.Run("generate", func(t *testing.T) {
+		t.Parallel()
+
+		g := NewDice(HumanOptions{Words: 3, Separator: "-"})
+		res, err := g.Generate(strings.NewReader("11111 11112\n6666 6\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res != "abacus-abdomen-zoom" {
+			t.Errorf("got %q", res)
+		}
+	})
+
+	t.Run("skip_repeat", func(t *testing.T) {
+		t.Parallel()
+
+		g := NewDice(HumanOptions{Words: 2, Separator: " "})
+		res, err := g.Generate(strings.NewReader("11111 11111 66666"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res != "abacus zoom" {
+			t.Errorf("got %q", res)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		g := NewDice(HumanOptions{Words: 2})
+		for _, rolls := range []string{"11111", "11111 1117", "11111 x"} {
+			if _, err := g.Generate(strings.NewReader(rolls)); !errors.Is(err, ErrInvalidRoll) {
+				t.Errorf("%q: %v should be %q", rolls, err, ErrInvalidRoll)
+			}
+		}
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		t.Parallel()
+
+		g := NewDice(HumanOptions{Words: -1})
+		if _, err := g.Generate(strings.NewReader("11111")); !errors.Is(err, ErrNegativeLength) {
+			t.Errorf("%v should be %q", err, ErrNegativeLength)
+		}
+	})
+
+	t.Run("not_diceware", func(t *testing.T) {
+		t.Parallel()
+
+		w := &Wordlist{Name: "small", Words: []string{"alpha", "bravo", "charlie"}}
+		if w.DiceDigits() != 0 {
+			t.Errorf("%d words should have no diceware numbering", len(w.Words))
+		}
+		g := NewDice(HumanOptions{Words: 1, Wordlist: w})
+		if _, err := g.Generate(strings.NewReader("1")); !errors.Is(err, ErrInvalidWordlist) {
+			t.Errorf("%v should be %q", err, ErrInvalidWordlist)
+		}
+	})
+}
@@ -0,0 +1,195 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrEntropyUnreachable is the error returned when no length or word count
+// reaches the requested entropy.
+var ErrEntropyUnreachable = errors.New("target entropy cannot be reached")
+
+// maxEntropyLength is the largest length or word count ForEntropy tries.
+const maxEntropyLength = 1024
+
+// Entropy returns the entropy in bits of the passwords generated by g: the
+// base-2 logarithm of the number of equally likely passwords.
+// Capitalization and the separator are deterministic and add nothing.
+func (g *Human) Entropy() (float64, error) {
+	words := g.opts.words()
+	if g.opts.Words < 0 {
+		return 0, fmt.Errorf("%w: %d words", ErrNegativeLength, g.opts.Words)
+	}
+	if !g.opts.AllowRepeat && g.opts.Words > len(words) {
+		return 0, ErrTooManyCharacters
+	}
+	return selectionEntropy(len(words), g.opts.Words, g.opts.AllowRepeat), nil
+}
+
+// Entropy returns the entropy in bits of the PIN codes generated by g,
+// once the weak patterns of the options are filtered out. The PIN codes of
+// the blocklist are not taken into account, so the result is slightly
+// overestimated for short PIN codes.
+func (g *PIN) Entropy() (float64, error) {
+	digits := g.opts.digits()
+	if g.opts.Length < 0 {
+		return 0, fmt.Errorf("%w: length %d", ErrNegativeLength, g.opts.Length)
+	}
+	if (len(digits) == 0 && g.opts.Length > 0) || (!g.opts.AllowRepeat && g.opts.Length > len(digits)) {
+		return 0, ErrTooManyCharacters
+	}
+	if g.opts.Avoid != (PINRules{}) {
+		return g.opts.rulesEntropy()
+	}
+	return selectionEntropy(len(digits), g.opts.Length, g.opts.AllowRepeat), nil
+}
+
+// Entropy returns the entropy in bits of the passwords generated by g.
+// It accounts for the class counts, the pool size of every class, repeats
+// and the length range of the policy. The MaxConsecutive rule is not taken
+// into account, so the result is an upper bound when it is set.
+func (g *Random) Entropy() (float64, error) {
+	pl, err := g.compile()
+	if err != nil {
+		return 0, err
+	}
+	return pl.entropy(), nil
+}
+
+// Entropy returns the entropy in bits of the passwords generated with p.
+func (p Policy) Entropy() (float64, error) {
+	return NewRandomWithPolicy(p).Entropy()
+}
+
+// entropy returns the entropy in bits of the plan. Each length of the range
+// is equally likely, then every password of that length is equally likely.
+func (pl *plan) entropy() float64 {
+	var (
+		lengths = pl.maxLength - pl.minLength + 1
+		sum     float64
+	)
+	for n := pl.minLength; n <= pl.maxLength; n++ {
+		t := pl.countTable()
+		nf, _ := math.Lgamma(float64(n) + 1)
+		sum += (nf + t[0][n]) / math.Ln2
+	}
+
+	return math.Log2(float64(lengths)) + sum/float64(lengths)
+}
+
+// selectionEntropy returns the entropy in bits of an ordered selection of k
+// elements out of n, with or without replacement.
+func selectionEntropy(n, k int, allowRepeat bool) float64 {
+	if k == 0 {
+		return 0
+	}
+	if allowRepeat {
+		return float64(k) * math.Log2(float64(n))
+	}
+
+	nf, _ := math.Lgamma(float64(n) + 1)
+	rf, _ := math.Lgamma(float64(n-k) + 1)
+	return (nf - rf) / math.Ln2
+}
+
+// ForEntropy returns the smallest length or word count n for which the
+// generator returned by gen(n) has at least the given bits of entropy.
+// gen is called with increasing values of n; a generator whose Entropy
+// fails with ErrTooManyCharacters marks the end of the search.
+//
+//	words, err := generator.ForEntropy(100, func(n int) generator.Generator {
+//		return generator.NewHuman(generator.HumanOptions{Words: n})
+//	})
+func ForEntropy(bits float64, gen func(n int) Generator) (int, error) {
+	var (
+		best    float64
+		lastErr error
+	)
+
+	// reaches reports whether n reaches the target and whether n exceeds
+	// the capacity of the generator.
+	reaches := func(n int) (ok, exceeded bool) {
+		h, err := gen(n).Entropy()
+		if err != nil {
+			lastErr = err
+			return false, errors.Is(err, ErrTooManyCharacters)
+		}
+		if h > best {
+			best = h
+		}
+		return h >= bits, false
+	}
+
+	unreachable := func() (int, error) {
+		if best == 0 && lastErr != nil {
+			// No candidate could be generated at all.
+			return 0, lastErr
+		}
+		return 0, fmt.Errorf("%w: %.2f bits requested but at most %.2f bits can be generated", ErrEntropyUnreachable, bits, best)
+	}
+
+	// Double n until the target is reached, then search the smallest n
+	// between the last two values: entropy grows with n.
+	lo, hi := 0, 1
+	for {
+		ok, exceeded := reaches(hi)
+		if ok {
+			break
+		}
+
+		if exceeded {
+			// Find the largest n within the capacity of the generator.
+			last := lo
+			for top := hi; top-last > 1; {
+				mid := (last + top) / 2
+				if _, exceeded := reaches(mid); exceeded {
+					top = mid
+				} else {
+					last = mid
+				}
+			}
+			if last == lo {
+				return unreachable()
+			}
+			if ok, _ := reaches(last); !ok {
+				return unreachable()
+			}
+			hi = last
+			break
+		}
+
+		if hi == maxEntropyLength {
+			return unreachable()
+		}
+		lo, hi = hi, 2*hi
+		if hi > maxEntropyLength {
+			hi = maxEntropyLength
+		}
+	}
+
+	for hi-lo > 1 {
+		mid := (lo + hi) / 2
+		if ok, _ := reaches(mid); ok {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+
+	return hi, nil
+}
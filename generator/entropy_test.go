@@ -0,0 +1,162 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestEntropy(t *testing.T) {
+	t.Parallel()
+
+	// 8!/(3!3!1!1!) orderings of 3 lower, 3 upper, 1 digit and 1 symbol.
+	fixed := math.Log2(1120) + 6*math.Log2(26) + math.Log2(10) + math.Log2(6)
+
+	tests := map[string]struct {
+		gen  Generator
+		want float64
+	}{
+		"human_repeat":    {NewHuman(HumanOptions{Words: 5, AllowRepeat: true}), 5 * math.Log2(7776)},
+		"human_no_repeat": {NewHuman(HumanOptions{Words: 2}), math.Log2(7776 * 7775)},
+		"pin_repeat":      {NewPIN(PINOptions{Length: 4, AllowRepeat: true}), 4 * math.Log2(10)},
+		"pin_no_repeat":   {NewPIN(PINOptions{Length: 4}), math.Log2(10 * 9 * 8 * 7)},
+		"pin_exclude":     {NewPIN(PINOptions{Length: 4, AllowRepeat: true, Exclude: Ambiguous}), 4 * math.Log2(8)},
+		"random_lower":    {NewRandom(RandomOptions{Length: 8, AllowRepeat: true}), 8 * math.Log2(26)},
+		"random_charset":  {NewRandom(RandomOptions{Length: 8, Charset: "äöüäöü", AllowRepeat: true}), 8 * math.Log2(3)},
+		"random_fixed": {
+			NewRandom(RandomOptions{Length: 8, Upper: true, Digits: true, Symbols: true, AllowRepeat: true}),
+			fixed,
+		},
+	}
+
+	for name, tt := range tests {
+		got, err := tt.gen.Entropy()
+		if err != nil {
+			t.Errorf("%s: %s", name, err)
+		}
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: entropy is %f, want %f", name, got, tt.want)
+		}
+	}
+}
+
+func TestPolicyEntropy(t *testing.T) {
+	t.Parallel()
+
+	p := Policy{
+		MinLength: 3,
+		MaxLength: 4,
+		Classes: []CharClass{
+			{Name: "ab", Chars: "ab", Min: 1},
+			{Name: "xyz", Chars: "xyz", Max: 2},
+		},
+	}
+
+	// Count the passwords of each length by brute force.
+	want := 1.0
+	var sum float64
+	for n := 3; n <= 4; n++ {
+		var count int
+		for _, s := range allStrings("abxyz", n) {
+			if countIn(s, "ab") >= 1 && countIn(s, "xyz") <= 2 && !hasDuplicate(strings.Split(s, "")) {
+				count++
+			}
+		}
+		sum += math.Log2(float64(count))
+	}
+	want += sum / 2
+
+	got, err := p.Entropy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(got-want) > 1e-9 {
+		t.Errorf("entropy is %f, want %f", got, want)
+	}
+}
+
+func allStrings(chars string, n int) []string {
+	if n == 0 {
+		return []string{""}
+	}
+
+	var result []string
+	for _, s := range allStrings(chars, n-1) {
+		for _, ch := range chars {
+			result = append(result, s+string(ch))
+		}
+	}
+	return result
+}
+
+func TestForEntropy(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		gen  func(n int) Generator
+		bits float64
+		want int
+	}{
+		"human": {
+			func(n int) Generator { return NewHuman(HumanOptions{Words: n}) },
+			100, 8,
+		},
+		"pin_repeat": {
+			func(n int) Generator { return NewPIN(PINOptions{Length: n, AllowRepeat: true}) },
+			100, 31,
+		},
+		"pin_no_repeat": {
+			func(n int) Generator { return NewPIN(PINOptions{Length: n}) },
+			math.Log2(10 * 9 * 8 * 7), 4,
+		},
+		"random": {
+			func(n int) Generator { return NewRandom(RandomOptions{Length: n, AllowRepeat: true}) },
+			100, 22,
+		},
+	}
+
+	for name, tt := range tests {
+		got, err := ForEntropy(tt.bits, tt.gen)
+		if err != nil {
+			t.Errorf("%s: %s", name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %d, want %d", name, got, tt.want)
+		}
+	}
+
+	t.Run("unreachable", func(t *testing.T) {
+		t.Parallel()
+
+		gens := map[string]func(n int) Generator{
+			"pin_no_repeat": func(n int) Generator { return NewPIN(PINOptions{Length: n}) },
+			"random_rules": func(n int) Generator {
+				return NewRandomWithPolicy(Policy{
+					MinLength: 4,
+					MaxLength: 8,
+					Classes:   []CharClass{{Name: "digit", Chars: Digits}},
+				}.WithLength(n))
+			},
+		}
+		for name, gen := range gens {
+			if _, err := ForEntropy(100, gen); !errors.Is(err, ErrEntropyUnreachable) {
+				t.Errorf("%s: %v should be %q", name, err, ErrEntropyUnreachable)
+			}
+		}
+	})
+}
@@ -0,0 +1,62 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package generator implements the password generation algorithms used by
+// the pwgenie command-line tool.
+//
+// Each generation mode is exposed as its own type implementing Generator:
+//
+//	g := generator.NewRandom(generator.RandomOptions{
+//		Length: 20,
+//		Upper:  true,
+//		Digits: true,
+//	})
+//	pass, err := g.Generate(rand.Reader)
+package generator
+
+import (
+	"errors"
+	"io"
+	"math"
+)
+
+// ErrTooManyCharacters is the error returned with the number of letters
+// exceeds the number of available letters and repeats are not allowed.
+var ErrTooManyCharacters = errors.New("number of characters exceeds available letters and repeats are not allowed")
+
+// ErrNegativeLength is the error returned when the number of characters
+// or words of a password is negative.
+var ErrNegativeLength = errors.New("negative number of characters or words")
+
+// Generator is the interface implemented by every password generation mode.
+type Generator interface {
+	// Generate returns a new password, reading randomness from r.
+	// r is usually crypto/rand.Reader.
+	Generate(r io.Reader) (string, error)
+	// Entropy returns the entropy in bits of the generated passwords.
+	Entropy() (float64, error)
+}
+
+// calcNum calculate the number of letters
+// based on character distribution in overall.
+func calcNum(total, avail, length, initVal int) int {
+	var result int
+
+	result = int(math.Floor(float64(avail) / float64(total) * float64(length)))
+	if result < 1 {
+		result = 1
+	}
+
+	return result * initVal
+}
@@ -0,0 +1,286 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"crypto/rand"
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+const N = 1000
+
+var r = rand.Reader
+
+func hasDuplicate(s []string) bool {
+	found := make(map[string]struct{}, len(s))
+	for _, e := range s {
+		if _, ok := found[e]; ok {
+			return true
+		}
+
+		found[e] = struct{}{}
+	}
+	return false
+}
+
+func Test_genHuman(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no_repeat", func(t *testing.T) {
+		t.Parallel()
+
+		res, err := genHuman(r, HumanOptions{Words: len(EFFWords), Separator: " "})
+		if err != nil {
+			t.Error(err)
+		}
+		if hasDuplicate(strings.Split(res, " ")) {
+			t.Errorf("%q should not have duplicates", res)
+		}
+	})
+
+	t.Run("no_repeat_failed", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := genHuman(r, HumanOptions{Words: len(EFFWords) + 1, Separator: " "})
+		if err != nil {
+			if !errors.Is(err, ErrTooManyCharacters) {
+				t.Errorf("%q should be %q", err, ErrTooManyCharacters)
+			}
+		}
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		t.Parallel()
+
+		for _, repeat := range []bool{false, true} {
+			if _, err := NewHuman(HumanOptions{Words: -1, AllowRepeat: repeat}).Generate(r); !errors.Is(err, ErrNegativeLength) {
+				t.Errorf("%v should be %q", err, ErrNegativeLength)
+			}
+		}
+	})
+}
+
+func Test_genRandom(t *testing.T) {
+	t.Parallel()
+	t.Run("gen_lowercase", func(t *testing.T) {
+		t.Parallel()
+
+		for i := 0; i < N; i++ {
+			res, err := genRandom(r, RandomOptions{Length: i % len(LowerLetters), AllowRepeat: true}.Policy())
+			if err != nil {
+				t.Error(err)
+			}
+			if res != strings.ToLower(res) {
+				t.Errorf("%q is not lowercase", res)
+			}
+		}
+	})
+
+	t.Run("gen_uppercase", func(t *testing.T) {
+		t.Parallel()
+
+		res, err := genRandom(r, RandomOptions{Length: N, Upper: true, AllowRepeat: true}.Policy())
+		if err != nil {
+			t.Error(err)
+		}
+		if res == strings.ToLower(res) {
+			t.Errorf("%q does not include uppercase", res)
+		}
+	})
+
+	t.Run("gen_symbol", func(t *testing.T) {
+		t.Parallel()
+
+		res, err := genRandom(r, RandomOptions{Length: N, Symbols: true, AllowRepeat: true}.Policy())
+		if err != nil {
+			t.Error(err)
+		}
+		if !strings.ContainsAny(res, Symbols) {
+			t.Errorf("%q does not include any symbols", res)
+		}
+	})
+
+	t.Run("gen_digit", func(t *testing.T) {
+		t.Parallel()
+
+		res, err := genRandom(r, RandomOptions{Length: N, Digits: true, AllowRepeat: true}.Policy())
+		if err != nil {
+			t.Error(err)
+		}
+		if !strings.ContainsAny(res, Digits) {
+			t.Errorf("%q does not include any digits", res)
+		}
+	})
+
+	t.Run("gen_no_repeat", func(t *testing.T) {
+		t.Parallel()
+
+		res, err := genRandom(r, RandomOptions{Length: len(LowerLetters + UpperLetters + Digits + Symbols), Upper: true, Digits: true, Symbols: true}.Policy())
+		if err != nil {
+			t.Error(err)
+		}
+		if hasDuplicate(strings.Split(res, "")) {
+			t.Errorf("%q should not have duplicate", res)
+		}
+	})
+
+	t.Run("gen_no_repeat_failed", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := genRandom(r, RandomOptions{Length: len(LowerLetters+UpperLetters+Digits+Symbols) + 1, Upper: true, Digits: true, Symbols: true}.Policy())
+		if err != nil {
+			if !errors.Is(err, ErrTooManyCharacters) {
+				t.Errorf("%q should be %q", err, ErrTooManyCharacters)
+			}
+		}
+	})
+}
+
+func Test_genRandomCharset(t *testing.T) {
+	t.Parallel()
+
+	const (
+		cyrillic = "абвгдеёжзийклмнопрстуфхцчшщъыьэюя"
+		symbols  = "§¤#"
+	)
+	opts := RandomOptions{Length: 36, Symbols: true, Charset: cyrillic, SymbolSet: symbols}
+
+	for i := 0; i < N; i++ {
+		res, err := genRandom(r, opts.Policy())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !utf8.ValidString(res) || utf8.RuneCountInString(res) != opts.Length {
+			t.Fatalf("%q is not %d valid characters", res, opts.Length)
+		}
+		if hasDuplicate(strings.Split(res, "")) {
+			t.Errorf("%q should not have duplicate", res)
+		}
+		if got := countIn(res, symbols); got != 3 {
+			t.Errorf("%q has %d symbols, want 3", res, got)
+		}
+		if got := countIn(res, cyrillic); got != 33 {
+			t.Errorf("%q has %d letters, want 33", res, got)
+		}
+	}
+
+	opts.Length++
+	if _, err := genRandom(r, opts.Policy()); !errors.Is(err, ErrTooManyCharacters) {
+		t.Errorf("%v should be %q", err, ErrTooManyCharacters)
+	}
+}
+
+func Test_genRandomExclude(t *testing.T) {
+	t.Parallel()
+
+	// 24 lower, 24 upper, 8 digits and 5 symbols are not ambiguous.
+	opts := RandomOptions{Length: 61, Upper: true, Digits: true, Symbols: true, Exclude: Ambiguous}
+	res, err := genRandom(r, opts.Policy())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.ContainsAny(res, Ambiguous) {
+		t.Errorf("%q includes ambiguous characters", res)
+	}
+	if got := countIn(res, Digits); got != 8 {
+		t.Errorf("%q has %d digits, want 8", res, got)
+	}
+
+	opts.Length++
+	if _, err := genRandom(r, opts.Policy()); !errors.Is(err, ErrTooManyCharacters) {
+		t.Errorf("%v should be %q", err, ErrTooManyCharacters)
+	}
+}
+
+func Test_genPIN(t *testing.T) {
+	t.Parallel()
+
+	t.Run("gen_no_repeat", func(t *testing.T) {
+		t.Parallel()
+		res, err := genPIN(r, PINOptions{Length: len(Digits) - 1})
+		if err != nil {
+			t.Error(err)
+		}
+
+		if hasDuplicate(strings.Split(res, "")) {
+			t.Errorf("%q should not have duplicate", res)
+		}
+	})
+
+	t.Run("gen_no_repeat_failed", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := genPIN(r, PINOptions{Length: len(Digits) + 1})
+		if err != nil {
+			if !errors.Is(err, ErrTooManyCharacters) {
+				t.Errorf("%q should be %q", err, ErrTooManyCharacters)
+			}
+		}
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		t.Parallel()
+
+		for _, repeat := range []bool{false, true} {
+			if _, err := NewPIN(PINOptions{Length: -1, AllowRepeat: repeat}).Generate(r); !errors.Is(err, ErrNegativeLength) {
+				t.Errorf("%v should be %q", err, ErrNegativeLength)
+			}
+		}
+	})
+}
+
+func Test_genPINExclude(t *testing.T) {
+	t.Parallel()
+
+	for i := 0; i < N; i++ {
+		res, err := genPIN(r, PINOptions{Length: 8, Exclude: Ambiguous})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.ContainsAny(res, "01") {
+			t.Fatalf("%q includes ambiguous digits", res)
+		}
+	}
+
+	if _, err := genPIN(r, PINOptions{Length: 9, Exclude: Ambiguous}); !errors.Is(err, ErrTooManyCharacters) {
+		t.Errorf("%v should be %q", err, ErrTooManyCharacters)
+	}
+	if _, err := genPIN(r, PINOptions{Length: 1, AllowRepeat: true, Exclude: Digits}); !errors.Is(err, ErrTooManyCharacters) {
+		t.Errorf("%v should be %q", err, ErrTooManyCharacters)
+	}
+}
+
+func TestGenerator(t *testing.T) {
+	t.Parallel()
+
+	gens := map[string]Generator{
+		"human":  NewHuman(HumanOptions{Words: 5, Separator: "-"}),
+		"random": NewRandom(RandomOptions{Length: 16, Upper: true, Digits: true, Symbols: true}),
+		"pin":    NewPIN(PINOptions{Length: 6}),
+	}
+
+	for name, gen := range gens {
+		res, err := gen.Generate(r)
+		if err != nil {
+			t.Errorf("%s: %s", name, err)
+		}
+		if res == "" {
+			t.Errorf("%s: generated password should not be empty", name)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+const (
+	// LowerLetters is the list of lowercase letters.
+	LowerLetters = "abcdefghijklmnopqrstuvwxyz"
+
+	// UpperLetters is the list of uppercase letters.
+	UpperLetters = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+	// Digits is the list of permitted digits.
+	Digits = "0123456789"
+
+	// Symbols is the list of symbols.
+	// Previous value "~!@#$%^&*()_+`-={}|[]\\:\"<>?,./"
+	// See https://github.com/1Password/spg/pull/22 for rationale in choice of symbols
+	Symbols = "!@.-_*"
+
+	// Ambiguous is the list of look-alike letters and digits, and of
+	// symbols easily confused with one another, when passwords are read
+	// aloud or typed from paper.
+	Ambiguous = "Il1|O0o" + "`'\",.;:"
+)
+
+// EFFWords (EFF's wordlist) <https://www.eff.org/deeplinks/2016/07/new-wordlists-random-passphrases>
+var EFFWords = WordlistEFFLarge.Words
@@ -0,0 +1,107 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/cases"
+)
+
+// HumanOptions configures a Human generator.
+type HumanOptions struct {
+	// Words is the number of words in the generated password.
+	Words int
+	// Separator is the separator for words in the generated password.
+	Separator string
+	// Capitalize enables capitalization of each word.
+	Capitalize bool
+	// AllowRepeat allows the same word to appear more than once.
+	AllowRepeat bool
+	// Wordlist is the list words are picked from, WordlistEFFLarge when
+	// nil.
+	Wordlist *Wordlist
+}
+
+// wordlist returns the wordlist of the options.
+func (o HumanOptions) wordlist() *Wordlist {
+	if o.Wordlist == nil {
+		return WordlistEFFLarge
+	}
+	return o.Wordlist
+}
+
+// words returns the words of the wordlist of the options.
+func (o HumanOptions) words() []string {
+	return o.wordlist().Words
+}
+
+// Human generates human-friendly memorable passwords from a wordlist.
+type Human struct {
+	opts HumanOptions
+}
+
+// NewHuman returns a Human generator with the given options.
+func NewHuman(opts HumanOptions) *Human {
+	return &Human{opts: opts}
+}
+
+// Generate implements Generator.
+func (g *Human) Generate(r io.Reader) (string, error) {
+	return genHuman(r, g.opts)
+}
+
+// genHuman generates a password with the given number of words, separated by the given
+// separator.
+// If capitalize is true, each word will be capitalized.
+func genHuman(r io.Reader, opts HumanOptions) (string, error) {
+	wordlist := opts.words()
+
+	if opts.Words < 0 {
+		return "", fmt.Errorf("%w: %d words", ErrNegativeLength, opts.Words)
+	}
+	if !opts.AllowRepeat && opts.Words > len(wordlist) {
+		return "", ErrTooManyCharacters
+	}
+
+	// Multiple choices from word list
+	indexes, err := newSampler(r).sample(len(wordlist), opts.Words, opts.AllowRepeat)
+	if err != nil {
+		return "", err
+	}
+
+	formatted := make([]string, len(indexes))
+	for i, j := range indexes {
+		formatted[i] = wordlist[j]
+	}
+
+	return formatWords(formatted, opts), nil
+}
+
+// formatWords joins the words with the separator and capitalizes them if
+// requested, with the casing rules of the language of the wordlist.
+func formatWords(words []string, opts HumanOptions) string {
+	// Join the formatted words with the separator
+	result := strings.Join(words, opts.Separator)
+
+	// Capitalize the result if requested
+	if opts.Capitalize {
+		result = cases.Title(opts.wordlist().Language).String(result)
+	}
+
+	return result
+}
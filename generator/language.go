@@ -0,0 +1,93 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Languages returns the languages of the built-in wordlists, English
+// first.
+func Languages() []language.Tag {
+	var (
+		tags []language.Tag
+		seen = make(map[language.Tag]bool)
+	)
+	for _, w := range Wordlists() {
+		if !seen[w.Language] {
+			seen[w.Language] = true
+			tags = append(tags, w.Language)
+		}
+	}
+	return tags
+}
+
+// WordlistForLanguage returns the built-in wordlist that best matches the
+// given language, e.g. WordlistBIP39ChineseTraditional for "zh-TW". The
+// English wordlist is WordlistEFFLarge.
+func WordlistForLanguage(tag language.Tag) (*Wordlist, bool) {
+	tags := Languages()
+	_, i, conf := language.NewMatcher(tags).Match(tag)
+	if conf < language.High {
+		return nil, false
+	}
+
+	for _, w := range Wordlists() {
+		if w.Language == tags[i] {
+			return w, true
+		}
+	}
+	return nil, false
+}
+
+// asciiReplacer transliterates the letters which are not a base letter
+// followed by diacritics in Unicode.
+var asciiReplacer = strings.NewReplacer(
+	"Æ", "AE", "æ", "ae", "Œ", "OE", "œ", "oe", "ß", "ss",
+	"Đ", "D", "đ", "d", "Ð", "D", "ð", "d", "Þ", "TH", "þ", "th",
+	"Ł", "L", "ł", "l", "Ø", "O", "ø", "o", "ı", "i",
+)
+
+// ASCII returns the wordlist with its words transliterated to ASCII,
+// e.g. "élève" becomes "eleve" and "đường" becomes "duong". Words which
+// become the same are kept once, with a warning. The returned errors wrap
+// ErrInvalidWordlist when a word has no ASCII transliteration, as in
+// Japanese.
+func (w *Wordlist) ASCII() (*Wordlist, error) {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+	words := make([]string, len(w.Words))
+	for i, word := range w.Words {
+		s, _, err := transform.String(t, asciiReplacer.Replace(word))
+		if err != nil {
+			return nil, err
+		}
+		for _, ch := range s {
+			if ch > unicode.MaxASCII {
+				return nil, fmt.Errorf("%w: %s: %q has no ASCII transliteration", ErrInvalidWordlist, w.Name, word)
+			}
+		}
+		words[i] = s
+	}
+
+	return newWordlist(w.Name+"-ascii", w.Language, words)
+}
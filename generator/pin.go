@@ -0,0 +1,89 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"fmt"
+	"io"
+)
+
+// PINOptions configures a PIN generator.
+type PINOptions struct {
+	// Length is the number of digits in the generated PIN code.
+	Length int
+	// AllowRepeat allows the same digit to appear more than once.
+	AllowRepeat bool
+	// Exclude lists the digits never used in the PIN code, e.g.
+	// Ambiguous.
+	Exclude string
+	// Avoid are the weak patterns never generated, e.g. StrongPINRules.
+	Avoid PINRules
+}
+
+// digits returns the digits the PIN codes are made of.
+func (o PINOptions) digits() []rune {
+	return []rune(removeRunes(Digits, o.Exclude))
+}
+
+// PIN generates random numeric PIN codes.
+type PIN struct {
+	opts PINOptions
+}
+
+// NewPIN returns a PIN generator with the given options.
+func NewPIN(opts PINOptions) *PIN {
+	return &PIN{opts: opts}
+}
+
+// Generate implements Generator.
+func (g *PIN) Generate(r io.Reader) (string, error) {
+	return genPIN(r, g.opts)
+}
+
+// genPIN generates a PIN with the given number of numbers. Notorious PIN
+// codes of the blocklist, such as 123456, and the weak patterns of
+// opts.Avoid are drawn again.
+func genPIN(r io.Reader, opts PINOptions) (string, error) {
+	digits := opts.digits()
+
+	if opts.Length < 0 {
+		return "", fmt.Errorf("%w: length %d", ErrNegativeLength, opts.Length)
+	}
+	if len(digits) == 0 && opts.Length > 0 {
+		return "", fmt.Errorf("%w: every digit is excluded", ErrTooManyCharacters)
+	}
+	if !opts.AllowRepeat && opts.Length > len(digits) {
+		return "", ErrTooManyCharacters
+	}
+
+	s := newSampler(r)
+	for attempt := 0; attempt < maxPolicyAttempts; attempt++ {
+		// Digits, in random order
+		indexes, err := s.sample(len(digits), opts.Length, opts.AllowRepeat)
+		if err != nil {
+			return "", err
+		}
+
+		result := make([]rune, len(indexes))
+		for i, j := range indexes {
+			result[i] = digits[j]
+		}
+		if !Blocked(string(result)) && !opts.Avoid.weak(string(result)) {
+			return string(result), nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: no PIN code outside the blocklist and the weak patterns found after %d attempts", ErrRejected, maxPolicyAttempts)
+}
@@ -0,0 +1,286 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// PINRules are the weak patterns a PIN generator avoids.
+type PINRules struct {
+	// Runs rejects ascending and descending runs such as 1234 or 6543.
+	Runs bool
+	// Repeats rejects repeated digits and blocks, such as 1111, 1212,
+	// 123123 or 112233.
+	Repeats bool
+	// Keypad rejects straight lines on a phone keypad, such as 2580 or
+	// 1470.
+	Keypad bool
+	// Dates rejects calendar dates, such as 0101, 311287 or 19871231.
+	Dates bool
+	// Years rejects PIN codes starting or ending with a year between 1900
+	// and 2099.
+	Years bool
+}
+
+// StrongPINRules avoids every weak pattern.
+var StrongPINRules = PINRules{Runs: true, Repeats: true, Keypad: true, Dates: true, Years: true}
+
+// pinRuleNames are the names of the rules accepted by ParsePINRules.
+var pinRuleNames = []string{"runs", "repeats", "keypad", "dates", "years"}
+
+// keypadLines are the straight lines of three keys of a phone keypad,
+// rows excepted as they are runs. Their reverses are lines too.
+var keypadLines = []string{"147", "258", "369", "580", "159", "357"}
+
+// ParsePINRules parses a comma-separated list of rule names: runs,
+// repeats, keypad, dates and years.
+func ParsePINRules(s string) (PINRules, error) {
+	var rules PINRules
+	for _, name := range strings.Split(s, ",") {
+		switch strings.TrimSpace(name) {
+		case "runs":
+			rules.Runs = true
+		case "repeats":
+			rules.Repeats = true
+		case "keypad":
+			rules.Keypad = true
+		case "dates":
+			rules.Dates = true
+		case "years":
+			rules.Years = true
+		default:
+			return PINRules{}, fmt.Errorf("unknown PIN rule %q, want one of %s", name, strings.Join(pinRuleNames, ", "))
+		}
+	}
+	return rules, nil
+}
+
+// Names returns the names of the enabled rules.
+func (r PINRules) Names() []string {
+	var names []string
+	for i, on := range []bool{r.Runs, r.Repeats, r.Keypad, r.Dates, r.Years} {
+		if on {
+			names = append(names, pinRuleNames[i])
+		}
+	}
+	return names
+}
+
+// weak reports whether the PIN code matches one of the rules.
+func (r PINRules) weak(pin string) bool {
+	return r.Runs && hasRun(pin) ||
+		r.Repeats && hasRepeat(pin) ||
+		r.Keypad && hasKeypadLine(pin) ||
+		r.Dates && isDate(pin) ||
+		r.Years && hasYear(pin)
+}
+
+// runLimit returns the length from which a run of digits makes a PIN code
+// of n digits weak: 3, or more than half of the digits for long PIN codes.
+func runLimit(n int) int {
+	if n/2+1 > 3 {
+		return n/2 + 1
+	}
+	return 3
+}
+
+// hasRun reports whether pin has an ascending or descending run of
+// runLimit digits.
+func hasRun(pin string) bool {
+	limit := runLimit(len(pin))
+	for _, step := range []int{1, -1} {
+		n := 1
+		for i := 1; i < len(pin); i++ {
+			if int(pin[i])-int(pin[i-1]) == step {
+				n++
+			} else {
+				n = 1
+			}
+			if n >= limit {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasRepeat reports whether pin has runLimit identical digits in a row, is
+// a block repeated several times, or is made of doubled digits.
+func hasRepeat(pin string) bool {
+	limit := runLimit(len(pin))
+	n := 1
+	for i := 1; i < len(pin); i++ {
+		if pin[i] == pin[i-1] {
+			n++
+		} else {
+			n = 1
+		}
+		if n >= limit {
+			return true
+		}
+	}
+
+	for b := 1; b <= len(pin)/2; b++ {
+		if len(pin)%b == 0 && strings.Repeat(pin[:b], len(pin)/b) == pin {
+			return true
+		}
+	}
+
+	if len(pin) < 4 || len(pin)%2 != 0 {
+		return false
+	}
+	for i := 0; i < len(pin); i += 2 {
+		if pin[i] != pin[i+1] {
+			return false
+		}
+	}
+	return true
+}
+
+// hasKeypadLine reports whether pin has a straight line of keypadLines.
+func hasKeypadLine(pin string) bool {
+	for _, line := range keypadLines {
+		if strings.Contains(pin, line) || strings.Contains(pin, reverse(line)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isDate reports whether pin is a calendar date: DDMM or MMDD for 4 digits,
+// DDMMYY, MMDDYY or YYMMDD for 6 digits and DDMMYYYY, MMDDYYYY or YYYYMMDD
+// for 8 digits.
+func isDate(pin string) bool {
+	num := func(s string) int {
+		n, _ := strconv.Atoi(s)
+		return n
+	}
+	switch len(pin) {
+	case 4:
+		return validDate(num(pin[0:2]), num(pin[2:4])) || validDate(num(pin[2:4]), num(pin[0:2]))
+	case 6:
+		return validDate(num(pin[0:2]), num(pin[2:4])) || validDate(num(pin[2:4]), num(pin[0:2])) ||
+			validDate(num(pin[4:6]), num(pin[2:4]))
+	case 8:
+		if y := num(pin[4:8]); y >= 1900 && y <= 2099 &&
+			(validDate(num(pin[0:2]), num(pin[2:4])) || validDate(num(pin[2:4]), num(pin[0:2]))) {
+			return true
+		}
+		y := num(pin[0:4])
+		return y >= 1900 && y <= 2099 && validDate(num(pin[6:8]), num(pin[4:6]))
+	}
+	return false
+}
+
+// validDate reports whether day and month are a valid day of a year,
+// February 29 included.
+func validDate(day, month int) bool {
+	days := [...]int{31, 29, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}
+	return month >= 1 && month <= 12 && day >= 1 && day <= days[month-1]
+}
+
+// hasYear reports whether pin starts or ends with a year between 1900 and
+// 2099.
+func hasYear(pin string) bool {
+	if len(pin) < 4 {
+		return false
+	}
+	for _, s := range []string{pin[:4], pin[len(pin)-4:]} {
+		if y, _ := strconv.Atoi(s); y >= 1900 && y <= 2099 {
+			return true
+		}
+	}
+	return false
+}
+
+// reverse returns s reversed. s must be ASCII.
+func reverse(s string) string {
+	b := []byte(s)
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return string(b)
+}
+
+// maxPINEnumeration is the number of PIN codes above which the share of
+// the PIN codes avoiding the rules is estimated from pinEntropySamples
+// PIN codes drawn at random instead of counted.
+const (
+	maxPINEnumeration = 1000000
+	pinEntropySamples = 100000
+)
+
+// rulesEntropy returns the entropy in bits of the PIN codes avoiding the
+// rules: the PIN codes of opts are counted, or estimated from a sample
+// for long PIN codes.
+func (o PINOptions) rulesEntropy() (float64, error) {
+	digits := o.digits()
+	total := selectionEntropy(len(digits), o.Length, o.AllowRepeat)
+
+	var kept, seen float64
+	if total <= math.Log2(maxPINEnumeration) {
+		pin := make([]rune, o.Length)
+		used := make([]bool, len(digits))
+		var walk func(i int)
+		walk = func(i int) {
+			if i == len(pin) {
+				seen++
+				if !o.Avoid.weak(string(pin)) {
+					kept++
+				}
+				return
+			}
+			for j, d := range digits {
+				if used[j] {
+					continue
+				}
+				pin[i] = d
+				used[j] = !o.AllowRepeat
+				walk(i + 1)
+				used[j] = false
+			}
+		}
+		walk(0)
+	} else {
+		// A fixed seed keeps the estimate stable between runs.
+		rnd := rand.New(rand.NewSource(1))
+		for ; seen < pinEntropySamples; seen++ {
+			pin := make([]rune, o.Length)
+			if o.AllowRepeat {
+				for i := range pin {
+					pin[i] = digits[rnd.Intn(len(digits))]
+				}
+			} else {
+				perm := rnd.Perm(len(digits))
+				for i := range pin {
+					pin[i] = digits[perm[i]]
+				}
+			}
+			if !o.Avoid.weak(string(pin)) {
+				kept++
+			}
+		}
+	}
+
+	if kept == 0 {
+		return 0, fmt.Errorf("%w: every PIN code of %d digits matches a weak pattern", ErrRejected, o.Length)
+	}
+	return total + math.Log2(kept/seen), nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPINRules(t *testing.T) {
+	t.Parallel()
+
+	t.Run("patterns", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			pin  string
+			rule PINRules
+		}{
+			{"1234", PINRules{Runs: true}},
+			{"9654", PINRules{Runs: true}},
+			{"812345", PINRules{Runs: true}},
+			{"1111", PINRules{Repeats: true}},
+			{"1212", PINRules{Repeats: true}},
+			{"123123", PINRules{Repeats: true}},
+			{"112233", PINRules{Repeats: true}},
+			{"2580", PINRules{Keypad: true}},
+			{"1470", PINRules{Keypad: true}},
+			{"9513", PINRules{Keypad: true}},
+			{"0101", PINRules{Dates: true}},
+			{"3112", PINRules{Dates: true}},
+			{"311287", PINRules{Dates: true}},
+			{"19871231", PINRules{Dates: true}},
+			{"1987", PINRules{Years: true}},
+			{"342019", PINRules{Years: true}},
+		}
+		for _, tt := range tests {
+			if !tt.rule.weak(tt.pin) {
+				t.Errorf("%q should match %v", tt.pin, tt.rule.Names())
+			}
+		}
+
+		for _, pin := range []string{"3946", "7302", "840691", "52093618"} {
+			if StrongPINRules.weak(pin) {
+				t.Errorf("%q should not be weak", pin)
+			}
+		}
+	})
+
+	t.Run("parse", func(t *testing.T) {
+		t.Parallel()
+
+		rules, err := ParsePINRules("runs, keypad,years")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rules != (PINRules{Runs: true, Keypad: true, Years: true}) {
+			t.Errorf("unexpected rules %+v", rules)
+		}
+		if _, err := ParsePINRules("runs,dots"); err == nil {
+			t.Error("unknown rule should fail")
+		}
+	})
+
+	t.Run("generate", func(t *testing.T) {
+		t.Parallel()
+
+		g := NewPIN(PINOptions{Length: 4, AllowRepeat: true, Avoid: StrongPINRules})
+		for i := 0; i < N; i++ {
+			res, err := g.Generate(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if StrongPINRules.weak(res) {
+				t.Fatalf("%q should have been drawn again", res)
+			}
+		}
+	})
+
+	t.Run("entropy", func(t *testing.T) {
+		t.Parallel()
+
+		for _, length := range []int{4, 8} {
+			plain, err := NewPIN(PINOptions{Length: length}).Entropy()
+			if err != nil {
+				t.Fatal(err)
+			}
+			strong, err := NewPIN(PINOptions{Length: length, Avoid: StrongPINRules}).Entropy()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strong >= plain || strong < plain-1 {
+				t.Errorf("length %d: entropy %.2f should be a bit lower than %.2f", length, strong, plain)
+			}
+		}
+
+		// 012 and 210 are runs, the 4 other orders are not.
+		bits, err := NewPIN(PINOptions{Length: 3, Exclude: "3456789", Avoid: PINRules{Runs: true}}).Entropy()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := 2.0; bits < want-1e-9 || bits > want+1e-9 {
+			t.Errorf("got entropy %.2f, want %.2f", bits, want)
+		}
+
+		_, err = NewPIN(PINOptions{Length: 3, AllowRepeat: true, Exclude: "123456789", Avoid: PINRules{Repeats: true}}).Entropy()
+		if !errors.Is(err, ErrRejected) {
+			t.Errorf("%v should be %q", err, ErrRejected)
+		}
+	})
+}
@@ -0,0 +1,434 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ErrUnsatisfiablePolicy is the error returned when no password can
+// satisfy the given policy.
+var ErrUnsatisfiablePolicy = errors.New("password policy cannot be satisfied")
+
+// maxPolicyAttempts is the number of candidates genRandom draws before it
+// gives up on a policy whose MaxConsecutive rule keeps rejecting them.
+const maxPolicyAttempts = 1000
+
+// CharClass is a named set of characters with limits on how many of them
+// may appear in a password.
+type CharClass struct {
+	// Name identifies the class in error messages, e.g. "digit".
+	Name string
+	// Chars is the set of characters of this class. It may hold any
+	// Unicode characters, e.g. accented or Cyrillic letters.
+	Chars string
+	// Min is the minimum number of characters from this class.
+	Min int
+	// Max is the maximum number of characters from this class.
+	// Zero means no limit.
+	Max int
+}
+
+// Policy describes the passwords genRandom is allowed to produce.
+//
+// Every character of a generated password belongs to exactly one class. A
+// character listed in several classes is counted in the first one only.
+type Policy struct {
+	// MinLength and MaxLength bound the length of the password. The length
+	// is picked uniformly in that range. A zero MaxLength means MinLength.
+	MinLength int
+	MaxLength int
+	// Classes are the character classes a password is made of.
+	Classes []CharClass
+	// Allowed, if not empty, restricts every class to these characters.
+	Allowed string
+	// Forbidden characters never appear in a password.
+	Forbidden string
+	// MaxConsecutive is the maximum number of consecutive identical
+	// characters. Zero means no limit.
+	MaxConsecutive int
+	// AllowRepeat allows the same character to appear more than once.
+	AllowRepeat bool
+}
+
+// Policy returns the policy equivalent to the options: every enabled class
+// gets the fixed number of characters computed by calcNum and the rest are
+// lowercase letters, or the characters of Charset. The counts are computed
+// from the classes without the excluded characters.
+func (o RandomOptions) Policy() Policy {
+	var maxChars, numLowerChars, numUpperChars, numDigits, numSymbols int
+
+	lowerName, lower := "lower", LowerLetters
+	if o.Charset != "" {
+		lowerName, lower = "charset", uniqueRunes(o.Charset)
+	}
+	symbols := Symbols
+	if o.SymbolSet != "" {
+		symbols = uniqueRunes(o.SymbolSet)
+	}
+	lower = removeRunes(lower, o.Exclude)
+	upper := removeRunes(UpperLetters, o.Exclude)
+	digits := removeRunes(Digits, o.Exclude)
+	symbols = removeRunes(symbols, o.Exclude)
+
+	maxChars += utf8.RuneCountInString(lower)
+	if o.Upper {
+		maxChars += utf8.RuneCountInString(upper)
+		numUpperChars = 1
+	}
+
+	if o.Digits {
+		maxChars += utf8.RuneCountInString(digits)
+		numDigits = 1
+	}
+
+	if o.Symbols {
+		maxChars += utf8.RuneCountInString(symbols)
+		numSymbols = 1
+	}
+
+	// calculate characters distributions
+	numUpperChars = calcNum(maxChars, utf8.RuneCountInString(upper), o.Length, numUpperChars)
+	numDigits = calcNum(maxChars, utf8.RuneCountInString(digits), o.Length, numDigits)
+	numSymbols = calcNum(maxChars, utf8.RuneCountInString(symbols), o.Length, numSymbols)
+
+	// The rest is lowercase characters
+	numLowerChars = o.Length - numUpperChars - numDigits - numSymbols
+
+	p := Policy{
+		MinLength:   o.Length,
+		MaxLength:   o.Length,
+		Forbidden:   o.Exclude,
+		AllowRepeat: o.AllowRepeat,
+	}
+	// A length shorter than the enabled classes leaves no lowercase
+	// letters: compile rejects the policy.
+	if numLowerChars > 0 {
+		p.Classes = append(p.Classes, CharClass{Name: lowerName, Chars: lower, Min: numLowerChars, Max: numLowerChars})
+	}
+	if o.Upper {
+		p.Classes = append(p.Classes, CharClass{Name: "upper", Chars: upper, Min: numUpperChars, Max: numUpperChars})
+	}
+	if o.Digits {
+		p.Classes = append(p.Classes, CharClass{Name: "digit", Chars: digits, Min: numDigits, Max: numDigits})
+	}
+	if o.Symbols {
+		p.Classes = append(p.Classes, CharClass{Name: "symbol", Chars: symbols, Min: numSymbols, Max: numSymbols})
+	}
+
+	return p
+}
+
+// policyClass is a CharClass resolved against the rest of a Policy.
+type policyClass struct {
+	name string
+	pool []rune
+	min  int
+	// max is the effective maximum, -1 when there is no limit.
+	max int
+}
+
+// plan is a validated Policy, ready for sampling. It is safe for
+// concurrent use.
+type plan struct {
+	minLength, maxLength int
+	classes              []policyClass
+	maxConsecutive       int
+	allowRepeat          bool
+
+	tableOnce sync.Once
+	table     [][]float64
+}
+
+// compile validates the policy and resolves the pools of its classes.
+// The returned errors wrap ErrUnsatisfiablePolicy.
+func (p Policy) compile() (*plan, error) {
+	minLength, maxLength := p.MinLength, p.MaxLength
+	if maxLength == 0 {
+		maxLength = minLength
+	}
+	if minLength < 0 {
+		return nil, fmt.Errorf("%w: negative minimum length %d", ErrUnsatisfiablePolicy, minLength)
+	}
+	if maxLength < minLength {
+		return nil, fmt.Errorf("%w: maximum length %d is less than minimum length %d", ErrUnsatisfiablePolicy, maxLength, minLength)
+	}
+	if p.MaxConsecutive < 0 {
+		return nil, fmt.Errorf("%w: negative maximum consecutive characters %d", ErrUnsatisfiablePolicy, p.MaxConsecutive)
+	}
+
+	var (
+		seen           = make(map[rune]bool)
+		sumMin, sumCap int
+		unlimited      bool
+		classes        = make([]policyClass, 0, len(p.Classes))
+	)
+	for _, c := range p.Classes {
+		if c.Min < 0 || c.Max < 0 {
+			return nil, fmt.Errorf("%w: class %q has a negative limit", ErrUnsatisfiablePolicy, c.Name)
+		}
+		if c.Max != 0 && c.Min > c.Max {
+			return nil, fmt.Errorf("%w: class %q requires at least %d characters but allows at most %d", ErrUnsatisfiablePolicy, c.Name, c.Min, c.Max)
+		}
+
+		var pool []rune
+		for _, ch := range c.Chars {
+			if seen[ch] || strings.ContainsRune(p.Forbidden, ch) ||
+				(p.Allowed != "" && !strings.ContainsRune(p.Allowed, ch)) {
+				continue
+			}
+			seen[ch] = true
+			pool = append(pool, ch)
+		}
+
+		pc := policyClass{name: c.Name, pool: pool, min: c.Min, max: -1}
+		if c.Max != 0 {
+			pc.max = c.Max
+		}
+		if len(pc.pool) == 0 {
+			pc.max = 0
+		} else if !p.AllowRepeat && (pc.max < 0 || pc.max > len(pc.pool)) {
+			pc.max = len(pc.pool)
+		}
+		if pc.min > 0 && len(pc.pool) == 0 {
+			return nil, fmt.Errorf("%w: class %q has no usable characters", ErrUnsatisfiablePolicy, c.Name)
+		}
+		if pc.min > pc.max && pc.max >= 0 {
+			return nil, fmt.Errorf("%w: %w: class %q requires %d characters but only %d are available",
+				ErrUnsatisfiablePolicy, ErrTooManyCharacters, c.Name, pc.min, len(pc.pool))
+		}
+
+		sumMin += pc.min
+		if pc.max < 0 {
+			unlimited = true
+		} else {
+			sumCap += pc.max
+		}
+		classes = append(classes, pc)
+	}
+
+	if sumMin > maxLength && minLength == maxLength {
+		return nil, fmt.Errorf("%w: length %d is shorter than the %d characters required by the classes", ErrUnsatisfiablePolicy, maxLength, sumMin)
+	}
+	if sumMin > maxLength {
+		return nil, fmt.Errorf("%w: classes require at least %d characters but the maximum length is %d", ErrUnsatisfiablePolicy, sumMin, maxLength)
+	}
+	if !unlimited && sumCap < minLength {
+		if p.AllowRepeat {
+			return nil, fmt.Errorf("%w: classes allow at most %d characters but the minimum length is %d", ErrUnsatisfiablePolicy, sumCap, minLength)
+		}
+		return nil, fmt.Errorf("%w: %w: only %d characters are available but the minimum length is %d", ErrUnsatisfiablePolicy, ErrTooManyCharacters, sumCap, minLength)
+	}
+	if p.MaxConsecutive == 1 && p.AllowRepeat && maxLength > 1 && len(seen) < 2 {
+		return nil, fmt.Errorf("%w: a single character cannot fill %d positions without consecutive repeats", ErrUnsatisfiablePolicy, maxLength)
+	}
+
+	// Keep only the lengths every class can agree on.
+	if sumMin > minLength {
+		minLength = sumMin
+	}
+	if !unlimited && sumCap < maxLength {
+		maxLength = sumCap
+	}
+
+	return &plan{
+		minLength:      minLength,
+		maxLength:      maxLength,
+		classes:        classes,
+		maxConsecutive: p.MaxConsecutive,
+		allowRepeat:    p.AllowRepeat,
+	}, nil
+}
+
+// limit returns the largest number of characters class c can contribute to
+// a password of length n.
+func (c policyClass) limit(n int) int {
+	if c.max < 0 || c.max > n {
+		return n
+	}
+	return c.max
+}
+
+// logWeight returns the natural logarithm of the number of ways to draw an
+// ordered sequence of k characters from the class, divided by k!.
+func (c policyClass) logWeight(k int, allowRepeat bool) float64 {
+	if k == 0 {
+		return 0
+	}
+	s := float64(len(c.pool))
+	kf, _ := math.Lgamma(float64(k) + 1)
+	if allowRepeat {
+		return float64(k)*math.Log(s) - kf
+	}
+	sf, _ := math.Lgamma(s + 1)
+	rf, _ := math.Lgamma(s - float64(k) + 1)
+	return sf - rf - kf
+}
+
+// countTable returns t where t[j][i] is the natural logarithm of the sum,
+// over all ways to split i characters among classes j.., of the product of
+// the class weights, for every length i up to the maximum length. The
+// number of passwords of length n is n! * exp(t[0][n]).
+//
+// The table is computed once per plan, as it is the same for every length.
+func (pl *plan) countTable() [][]float64 {
+	pl.tableOnce.Do(func() {
+		n, m := pl.maxLength, len(pl.classes)
+		t := make([][]float64, m+1)
+		for j := range t {
+			t[j] = make([]float64, n+1)
+			for i := range t[j] {
+				t[j][i] = math.Inf(-1)
+			}
+		}
+		t[m][0] = 0
+
+		terms := make([]float64, 0, n+1)
+		for j := m - 1; j >= 0; j-- {
+			c := pl.classes[j]
+			weights := make([]float64, c.limit(n)+1)
+			for k := c.min; k < len(weights); k++ {
+				weights[k] = c.logWeight(k, pl.allowRepeat)
+			}
+			for i := 0; i <= n; i++ {
+				terms = terms[:0]
+				for k := c.min; k <= c.limit(i); k++ {
+					if rest := t[j+1][i-k]; !math.IsInf(rest, -1) {
+						terms = append(terms, weights[k]+rest)
+					}
+				}
+				t[j][i] = logSumExp(terms)
+			}
+		}
+		pl.table = t
+	})
+	return pl.table
+}
+
+// counts picks how many characters of each class a password of length n
+// contains, weighted by the number of passwords having those counts, so
+// that every valid password is equally likely.
+func (pl *plan) counts(s *sampler, n int) ([]int, error) {
+	t := pl.countTable()
+	if math.IsInf(t[0][n], -1) {
+		return nil, fmt.Errorf("%w: no combination of classes fills %d characters", ErrUnsatisfiablePolicy, n)
+	}
+
+	result := make([]int, len(pl.classes))
+	for j, c := range pl.classes {
+		u, err := s.float64()
+		if err != nil {
+			return nil, err
+		}
+
+		// Walk the cumulative distribution of k until it passes u.
+		k, acc := c.min, 0.0
+		for ; k < c.limit(n); k++ {
+			if rest := t[j+1][n-k]; !math.IsInf(rest, -1) {
+				acc += math.Exp(c.logWeight(k, pl.allowRepeat) + rest - t[j][n])
+				if acc > u {
+					break
+				}
+			}
+		}
+		for math.IsInf(t[j+1][n-k], -1) {
+			k--
+		}
+
+		result[j] = k
+		n -= k
+	}
+
+	return result, nil
+}
+
+// satisfies reports whether s respects the MaxConsecutive rule.
+func (pl *plan) satisfies(s string) bool {
+	if pl.maxConsecutive == 0 {
+		return true
+	}
+
+	var (
+		prev rune
+		run  int
+	)
+	for i, ch := range s {
+		if i > 0 && ch == prev {
+			run++
+		} else {
+			run = 1
+		}
+		if run > pl.maxConsecutive {
+			return false
+		}
+		prev = ch
+	}
+
+	return true
+}
+
+// uniqueRunes returns s without its duplicate characters.
+func uniqueRunes(s string) string {
+	var (
+		b    strings.Builder
+		seen = make(map[rune]bool)
+	)
+	for _, ch := range s {
+		if !seen[ch] {
+			seen[ch] = true
+			b.WriteRune(ch)
+		}
+	}
+	return b.String()
+}
+
+// removeRunes returns s without the characters of chars.
+func removeRunes(s, chars string) string {
+	if chars == "" {
+		return s
+	}
+	return strings.Map(func(ch rune) rune {
+		if strings.ContainsRune(chars, ch) {
+			return -1
+		}
+		return ch
+	}, s)
+}
+
+// logSumExp returns log(sum(exp(x))) without overflowing.
+func logSumExp(x []float64) float64 {
+	if len(x) == 0 {
+		return math.Inf(-1)
+	}
+
+	hi := x[0]
+	for _, v := range x[1:] {
+		if v > hi {
+			hi = v
+		}
+	}
+
+	var sum float64
+	for _, v := range x {
+		sum += math.Exp(v - hi)
+	}
+
+	return hi + math.Log(sum)
+}
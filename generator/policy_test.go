@@ -0,0 +1,146 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func countIn(s, chars string) int {
+	var n int
+	for _, ch := range s {
+		if strings.ContainsRune(chars, ch) {
+			n++
+		}
+	}
+	return n
+}
+
+func Test_genRandomPolicy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("class_limits", func(t *testing.T) {
+		t.Parallel()
+
+		p := Policy{
+			MinLength: 8,
+			MaxLength: 16,
+			Classes: []CharClass{
+				{Name: "lower", Chars: LowerLetters, Min: 1},
+				{Name: "digit", Chars: Digits, Min: 2},
+				{Name: "symbol", Chars: Symbols, Max: 3},
+			},
+			AllowRepeat: true,
+		}
+		for i := 0; i < N; i++ {
+			res, err := genRandom(r, p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(res) < 8 || len(res) > 16 {
+				t.Errorf("%q length is out of range", res)
+			}
+			if countIn(res, LowerLetters) < 1 || countIn(res, Digits) < 2 || countIn(res, Symbols) > 3 {
+				t.Errorf("%q does not satisfy the class limits", res)
+			}
+		}
+	})
+
+	t.Run("allowed_forbidden", func(t *testing.T) {
+		t.Parallel()
+
+		p := Policy{
+			MinLength: 32,
+			Classes: []CharClass{
+				{Name: "lower", Chars: LowerLetters, Min: 1},
+				{Name: "symbol", Chars: Symbols, Min: 1},
+			},
+			Allowed:     "abc" + Symbols,
+			Forbidden:   "c@",
+			AllowRepeat: true,
+		}
+		res, err := genRandom(r, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Trim(res, "ab!.-_*") != "" {
+			t.Errorf("%q contains characters that are not allowed", res)
+		}
+	})
+
+	t.Run("max_consecutive", func(t *testing.T) {
+		t.Parallel()
+
+		p := Policy{
+			MinLength:      16,
+			Classes:        []CharClass{{Name: "digit", Chars: "01"}},
+			MaxConsecutive: 2,
+			AllowRepeat:    true,
+		}
+		res, err := genRandom(r, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(res, "000") || strings.Contains(res, "111") {
+			t.Errorf("%q has more than 2 consecutive identical characters", res)
+		}
+	})
+
+	t.Run("unsatisfiable", func(t *testing.T) {
+		t.Parallel()
+
+		policies := map[string]Policy{
+			"min_exceeds_length": {
+				MinLength: 4,
+				Classes:   []CharClass{{Name: "digit", Chars: Digits, Min: 5}},
+			},
+			"min_exceeds_max": {
+				MinLength: 8,
+				Classes:   []CharClass{{Name: "digit", Chars: Digits, Min: 3, Max: 2}},
+			},
+			"all_forbidden": {
+				MinLength: 8,
+				Classes:   []CharClass{{Name: "digit", Chars: Digits, Min: 1}},
+				Forbidden: Digits,
+			},
+			"not_enough_chars": {
+				MinLength: 11,
+				Classes:   []CharClass{{Name: "digit", Chars: Digits}},
+			},
+		}
+		for name, p := range policies {
+			if _, err := genRandom(r, p); !errors.Is(err, ErrUnsatisfiablePolicy) {
+				t.Errorf("%s: %v should be %q", name, err, ErrUnsatisfiablePolicy)
+			}
+		}
+	})
+
+	t.Run("shorter_than_classes", func(t *testing.T) {
+		t.Parallel()
+
+		p := RandomOptions{Length: 2, Upper: true, Digits: true, Symbols: true}.Policy()
+		_, err := genRandom(r, p)
+		if !errors.Is(err, ErrUnsatisfiablePolicy) || !strings.Contains(err.Error(), "length 2 is shorter than the 3 characters") {
+			t.Errorf("unexpected error %v", err)
+		}
+
+		p = RandomOptions{Length: 3, Upper: true, Digits: true, Symbols: true}.Policy()
+		if res, err := genRandom(r, p); err != nil || len(res) != 3 {
+			t.Errorf("got %q, %v", res, err)
+		}
+	})
+}
@@ -0,0 +1,150 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+const (
+	// awsSymbols are the symbols counted by the password policy of AWS
+	// IAM.
+	awsSymbols = "!@#$%^&*()_+-=[]{}|'"
+	// adSymbols are the non-alphanumeric characters counted by the
+	// complexity requirements of Active Directory, without the space and
+	// the look-alike characters.
+	adSymbols = "~!@#$%^&*_-+=\\(){}[]<>?/"
+)
+
+// Preset maps a password policy, e.g. of a compliance standard, onto the
+// generators. The length or word count of each mode is the minimum
+// meeting the policy: longer passwords meet it too, up to the MaxLength
+// of Random if set.
+type Preset struct {
+	// Name identifies the preset, e.g. "pci-dss-4".
+	Name string
+	// Standard is the policy the preset meets, e.g. "PCI DSS 4.0".
+	Standard string
+	// Rationale sums up the requirements of the standard and how the
+	// preset meets them.
+	Rationale string
+	// Random, Human and PIN are the options of the modes meeting the
+	// policy, nil for the modes which cannot meet it.
+	Random *Policy
+	Human  *HumanOptions
+	PIN    *PINOptions
+}
+
+// Presets returns the built-in presets.
+func Presets() []Preset {
+	return []Preset{
+		{
+			Name:     "nist-800-63b",
+			Standard: "NIST SP 800-63B",
+			Rationale: "Memorized secrets of at least 15 characters when used as a single factor, " +
+				"without composition rules, screened against common, repetitive and sequential values; " +
+				"random numeric secrets of at least 6 digits.",
+			Random: &Policy{
+				MinLength: 15,
+				Classes:   []CharClass{{Name: "alphanumeric", Chars: LowerLetters + UpperLetters + Digits}},
+			},
+			// 5 words of 3 letters at least make 15 characters.
+			Human: &HumanOptions{Words: 5, Separator: " "},
+			PIN:   &PINOptions{Length: 6, Avoid: PINRules{Runs: true, Repeats: true}},
+		},
+		{
+			Name:     "pci-dss-4",
+			Standard: "PCI DSS 4.0",
+			Rationale: "Requirement 8.3.6: passwords of at least 12 characters " +
+				"containing both letters and digits.",
+			Random: &Policy{
+				MinLength: 12,
+				Classes: []CharClass{
+					{Name: "letter", Chars: LowerLetters + UpperLetters, Min: 1},
+					{Name: "digit", Chars: Digits, Min: 1},
+				},
+			},
+		},
+		{
+			Name:     "aws-iam",
+			Standard: "AWS IAM default password policy",
+			Rationale: "From 8 to 128 characters from 3 of 4 classes: upper-case, lower-case, digits and " +
+				awsSymbols + "; the preset includes all 4.",
+			Random: &Policy{
+				MinLength: 8,
+				MaxLength: 128,
+				Classes: []CharClass{
+					{Name: "lower", Chars: LowerLetters, Min: 1},
+					{Name: "upper", Chars: UpperLetters, Min: 1},
+					{Name: "digit", Chars: Digits, Min: 1},
+					{Name: "symbol", Chars: awsSymbols, Min: 1},
+				},
+			},
+		},
+		{
+			Name:     "windows-ad",
+			Standard: "Active Directory password complexity",
+			Rationale: "Characters from 3 of 4 classes: upper-case, lower-case, digits and non-alphanumeric; " +
+				"the preset includes all 4. At least 14 characters as in Microsoft's security baseline, " +
+				"above the 7 of the default domain policy.",
+			Random: &Policy{
+				MinLength: 14,
+				Classes: []CharClass{
+					{Name: "lower", Chars: LowerLetters, Min: 1},
+					{Name: "upper", Chars: UpperLetters, Min: 1},
+					{Name: "digit", Chars: Digits, Min: 1},
+					{Name: "symbol", Chars: adSymbols, Min: 1},
+				},
+			},
+		},
+		{
+			Name:     "cis",
+			Standard: "CIS Controls v8",
+			Rationale: "Safeguard 5.2: unique passwords of at least 14 characters for accounts " +
+				"without multi-factor authentication.",
+			Random: &Policy{
+				MinLength: 14,
+				Classes: []CharClass{
+					{Name: "lower", Chars: LowerLetters, Min: 1},
+					{Name: "upper", Chars: UpperLetters, Min: 1},
+					{Name: "digit", Chars: Digits, Min: 1},
+				},
+			},
+			// 5 words of 3 letters at least make 15 characters.
+			Human: &HumanOptions{Words: 5, Separator: " "},
+		},
+	}
+}
+
+// LookupPreset returns the built-in preset with the given name.
+func LookupPreset(name string) (Preset, bool) {
+	for _, p := range Presets() {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Preset{}, false
+}
+
+// Modes returns the names of the modes the preset applies to.
+func (p Preset) Modes() []string {
+	var modes []string
+	if p.Random != nil {
+		modes = append(modes, "random")
+	}
+	if p.Human != nil {
+		modes = append(modes, "human")
+	}
+	if p.PIN != nil {
+		modes = append(modes, "pin")
+	}
+	return modes
+}
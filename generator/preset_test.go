@@ -0,0 +1,82 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestPresets(t *testing.T) {
+	t.Parallel()
+
+	seen := make(map[string]bool)
+	for _, p := range Presets() {
+		if seen[p.Name] {
+			t.Errorf("duplicate preset %q", p.Name)
+		}
+		seen[p.Name] = true
+		if p.Standard == "" || p.Rationale == "" || len(p.Modes()) == 0 {
+			t.Errorf("%s: incomplete preset", p.Name)
+		}
+		if got, ok := LookupPreset(p.Name); !ok || got.Name != p.Name {
+			t.Errorf("%s: preset not found", p.Name)
+		}
+
+		for i := 0; i < 100; i++ {
+			if p.Random != nil {
+				pass, err := NewRandomWithPolicy(*p.Random).Generate(r)
+				if err != nil {
+					t.Fatalf("%s: %v", p.Name, err)
+				}
+				if n := utf8.RuneCountInString(pass); n < p.Random.MinLength {
+					t.Errorf("%s: %q is too short", p.Name, pass)
+				} else if p.Random.MaxLength != 0 && n > p.Random.MaxLength {
+					t.Errorf("%s: %q is too long", p.Name, pass)
+				}
+				for _, c := range p.Random.Classes {
+					if c.Min > 0 && !strings.ContainsAny(pass, c.Chars) {
+						t.Errorf("%s: %q has no %s character", p.Name, pass, c.Name)
+					}
+				}
+			}
+			if p.Human != nil {
+				opts := *p.Human
+				opts.Separator = ""
+				pass, err := NewHuman(opts).Generate(r)
+				if err != nil {
+					t.Fatalf("%s: %v", p.Name, err)
+				}
+				if utf8.RuneCountInString(pass) < 15 {
+					t.Errorf("%s: %q is too short", p.Name, pass)
+				}
+			}
+			if p.PIN != nil {
+				pass, err := NewPIN(*p.PIN).Generate(r)
+				if err != nil {
+					t.Fatalf("%s: %v", p.Name, err)
+				}
+				if len(pass) < p.PIN.Length || p.PIN.Avoid.weak(pass) {
+					t.Errorf("%s: %q does not meet the preset", p.Name, pass)
+				}
+			}
+		}
+	}
+
+	if _, ok := LookupPreset("unknown"); ok {
+		t.Error("unknown preset should not be found")
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// RandomOptions configures a Random generator.
+type RandomOptions struct {
+	// Length is the number of characters in the generated password.
+	Length int
+	// Upper enables the inclusion of upper-case letters.
+	Upper bool
+	// Digits enables the inclusion of digits.
+	Digits bool
+	// Symbols enables the inclusion of symbols.
+	Symbols bool
+	// AllowRepeat allows the same character to appear more than once.
+	AllowRepeat bool
+	// Charset, if not empty, replaces the lower-case letters the password
+	// is mostly made of, e.g. with a localized alphabet. It may hold any
+	// Unicode characters.
+	Charset string
+	// SymbolSet, if not empty, replaces the symbols included by Symbols.
+	SymbolSet string
+	// Exclude lists the characters never used in the password, e.g.
+	// Ambiguous. They are removed from the classes before the number of
+	// characters of each class is computed.
+	Exclude string
+}
+
+// Random generates random passwords with the specified complexity. It is
+// safe for concurrent use.
+type Random struct {
+	policy Policy
+
+	once sync.Once
+	plan *plan
+	err  error
+}
+
+// NewRandom returns a Random generator with the given options.
+func NewRandom(opts RandomOptions) *Random {
+	return &Random{policy: opts.Policy()}
+}
+
+// NewRandomWithPolicy returns a Random generator producing passwords that
+// satisfy p.
+func NewRandomWithPolicy(p Policy) *Random {
+	return &Random{policy: p}
+}
+
+// Generate implements Generator.
+func (g *Random) Generate(r io.Reader) (string, error) {
+	pl, err := g.compile()
+	if err != nil {
+		return "", err
+	}
+	return pl.generate(r)
+}
+
+// compile returns the plan of the policy of g, compiled on first use and
+// shared by the passwords generated by g.
+func (g *Random) compile() (*plan, error) {
+	g.once.Do(func() { g.plan, g.err = g.policy.compile() })
+	return g.plan, g.err
+}
+
+// genRandom generates a password satisfying the given policy.
+// Class counts are drawn so that every password allowed by the policy is
+// equally likely; with the fixed counts of RandomOptions this follows
+// Agiles 1Password: https://discussions.agilebits.com/discussion/23842/how-random-are-the-generated-passwords
+//
+// The characters of every class are sampled from its pool, then the whole
+// password is shuffled once to mix the classes. Passwords on the blocklist
+// are drawn again.
+func genRandom(r io.Reader, p Policy) (string, error) {
+	pl, err := p.compile()
+	if err != nil {
+		return "", err
+	}
+	return pl.generate(r)
+}
+
+// generate generates a password satisfying the plan, see genRandom.
+func (pl *plan) generate(r io.Reader) (string, error) {
+	s := newSampler(r)
+	for attempt := 0; attempt < maxPolicyAttempts; attempt++ {
+		length := pl.minLength
+		if pl.maxLength > pl.minLength {
+			n, err := s.intn(pl.maxLength - pl.minLength + 1)
+			if err != nil {
+				return "", err
+			}
+			length += n
+		}
+
+		counts, err := pl.counts(s, length)
+		if err != nil {
+			return "", err
+		}
+
+		result := make([]rune, 0, length)
+		for i, c := range pl.classes {
+			indexes, err := s.sample(len(c.pool), counts[i], pl.allowRepeat)
+			if err != nil {
+				return "", err
+			}
+			for _, j := range indexes {
+				result = append(result, c.pool[j])
+			}
+		}
+
+		err = s.shuffle(len(result), func(i, j int) { result[i], result[j] = result[j], result[i] })
+		if err != nil {
+			return "", err
+		}
+
+		if pl.satisfies(string(result)) && !Blocked(string(result)) {
+			return string(result), nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: no password satisfying max-consecutive %d outside the blocklist found after %d attempts",
+		ErrUnsatisfiablePolicy, pl.maxConsecutive, maxPolicyAttempts)
+}
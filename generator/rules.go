@@ -0,0 +1,230 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidPasswordRules is the error returned when a passwordrules string
+// cannot be parsed.
+var ErrInvalidPasswordRules = errors.New("invalid password rules")
+
+// RulesSpecial is the "special" character class of the passwordrules syntax.
+// See https://developer.apple.com/password-rules/
+const RulesSpecial = "-~!@#$%^&*_+=`|(){}[:;\"'<>,.? ]"
+
+// rulesClasses maps the named classes of the passwordrules syntax to their
+// characters. Generated passwords are ASCII, so "unicode" is treated as
+// "ascii-printable".
+var rulesClasses = map[string]string{
+	"upper":           UpperLetters,
+	"lower":           LowerLetters,
+	"digit":           Digits,
+	"special":         RulesSpecial,
+	"ascii-printable": asciiPrintable(),
+	"unicode":         asciiPrintable(),
+}
+
+// ParsePasswordRules parses a passwordrules string, the format used by
+// browsers and password managers to describe the passwords a website
+// accepts, e.g.
+//
+//	minlength: 20; required: upper; required: digit; allowed: [-_.!]; max-consecutive: 2;
+//
+// Every "required" rule becomes a class with a minimum of one character and
+// every "allowed" rule adds its characters to an optional class. Without
+// "required" and "allowed" rules, all printable ASCII characters are
+// allowed. "minlength" and "maxlength" set the length range; when
+// "minlength" is missing, MinLength is zero and the caller picks the
+// length with Policy.WithLength.
+func ParsePasswordRules(s string) (Policy, error) {
+	var (
+		p        Policy
+		allowed  strings.Builder
+		required []CharClass
+	)
+
+	for _, rule := range strings.Split(s, ";") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(rule, ":")
+		if !ok {
+			return p, fmt.Errorf("%w: rule %q has no value", ErrInvalidPasswordRules, rule)
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.TrimSpace(value)
+
+		switch name {
+		case "required", "allowed":
+			chars, err := parseRulesClasses(value)
+			if err != nil {
+				return p, err
+			}
+			if name == "required" {
+				required = append(required, CharClass{Name: value, Chars: chars, Min: 1})
+			} else {
+				allowed.WriteString(chars)
+			}
+		case "minlength", "maxlength", "max-consecutive":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return p, fmt.Errorf("%w: %s must be a non-negative integer, got %q", ErrInvalidPasswordRules, name, value)
+			}
+			switch {
+			case name == "minlength" && n > p.MinLength:
+				p.MinLength = n
+			case name == "maxlength" && (p.MaxLength == 0 || n < p.MaxLength):
+				p.MaxLength = n
+			case name == "max-consecutive" && (p.MaxConsecutive == 0 || n < p.MaxConsecutive):
+				p.MaxConsecutive = n
+			}
+		default:
+			return p, fmt.Errorf("%w: unknown property %q", ErrInvalidPasswordRules, name)
+		}
+	}
+
+	if p.MaxLength != 0 && p.MinLength > p.MaxLength {
+		return p, fmt.Errorf("%w: minlength %d is greater than maxlength %d", ErrInvalidPasswordRules, p.MinLength, p.MaxLength)
+	}
+
+	// A character is counted in the first class that lists it, so a
+	// required set overlapping another one could be left without
+	// characters. The sets containing another required set are implied by
+	// it, e.g. "upper, digit" by "digit": only their characters remain
+	// allowed. The other overlaps keep their own characters.
+	required, implied := dropImpliedClasses(required)
+	allowed.WriteString(implied)
+
+	p.Classes = required
+	if len(required) == 0 && allowed.Len() == 0 {
+		allowed.WriteString(rulesClasses["ascii-printable"])
+	}
+	if allowed.Len() > 0 {
+		p.Classes = append(p.Classes, CharClass{Name: "allowed", Chars: allowed.String()})
+	}
+
+	return p, nil
+}
+
+// dropImpliedClasses returns the required classes which do not contain
+// the characters of another required class, keeping the first of equal
+// classes, and the characters of the dropped classes.
+func dropImpliedClasses(required []CharClass) ([]CharClass, string) {
+	var (
+		kept    []CharClass
+		implied strings.Builder
+	)
+	for i, c := range required {
+		dropped := false
+		for j, other := range required {
+			if i == j || !containsAllRunes(c.Chars, other.Chars) {
+				continue
+			}
+			if j < i || !containsAllRunes(other.Chars, c.Chars) {
+				dropped = true
+				break
+			}
+		}
+		if dropped {
+			implied.WriteString(c.Chars)
+		} else {
+			kept = append(kept, c)
+		}
+	}
+	return kept, implied.String()
+}
+
+// containsAllRunes reports whether every rune of chars is in s.
+func containsAllRunes(s, chars string) bool {
+	for _, ch := range chars {
+		if !strings.ContainsRune(s, ch) {
+			return false
+		}
+	}
+	return true
+}
+
+// WithLength returns a copy of p producing passwords of length n, clamped
+// to the length range of p.
+func (p Policy) WithLength(n int) Policy {
+	if n < p.MinLength {
+		n = p.MinLength
+	}
+	if p.MaxLength != 0 && n > p.MaxLength {
+		n = p.MaxLength
+	}
+	p.MinLength, p.MaxLength = n, n
+	return p
+}
+
+// parseRulesClasses parses a comma-separated list of named classes and
+// custom classes such as [-_.!] and returns the union of their characters.
+func parseRulesClasses(s string) (string, error) {
+	var result strings.Builder
+
+	for s != "" {
+		s = strings.TrimLeft(s, " ,")
+		if s == "" {
+			break
+		}
+
+		if s[0] == '[' {
+			// ']' is a member of the class when it is directly followed
+			// by the closing bracket, e.g. [-]].
+			end := strings.IndexByte(s[1:], ']')
+			if end < 0 {
+				return "", fmt.Errorf("%w: unterminated custom class %q", ErrInvalidPasswordRules, s)
+			}
+			end++
+			if end+1 < len(s) && s[end+1] == ']' {
+				end++
+			}
+			result.WriteString(s[1:end])
+			s = s[end+1:]
+			continue
+		}
+
+		name := s
+		if i := strings.IndexByte(s, ','); i >= 0 {
+			name, s = s[:i], s[i+1:]
+		} else {
+			s = ""
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		chars, ok := rulesClasses[name]
+		if !ok {
+			return "", fmt.Errorf("%w: unknown character class %q", ErrInvalidPasswordRules, name)
+		}
+		result.WriteString(chars)
+	}
+
+	return result.String(), nil
+}
+
+// asciiPrintable returns the printable ASCII characters, space included.
+func asciiPrintable() string {
+	var b strings.Builder
+	for c := ' '; c <= '~'; c++ {
+		b.WriteRune(c)
+	}
+	return b.String()
+}
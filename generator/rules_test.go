@@ -0,0 +1,148 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParsePasswordRules(t *testing.T) {
+	t.Parallel()
+
+	t.Run("parse", func(t *testing.T) {
+		t.Parallel()
+
+		p, err := ParsePasswordRules("minlength: 20; maxlength: 32; required: upper; required: digit; allowed: [-_.!]; max-consecutive: 2;")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.MinLength != 20 || p.MaxLength != 32 || p.MaxConsecutive != 2 {
+			t.Errorf("unexpected limits %+v", p)
+		}
+		if len(p.Classes) != 3 {
+			t.Fatalf("expected 3 classes, got %+v", p.Classes)
+		}
+		if p.Classes[0].Chars != UpperLetters || p.Classes[0].Min != 1 {
+			t.Errorf("unexpected upper class %+v", p.Classes[0])
+		}
+		if p.Classes[2].Chars != "-_.!" || p.Classes[2].Min != 0 {
+			t.Errorf("unexpected allowed class %+v", p.Classes[2])
+		}
+	})
+
+	t.Run("custom_class_brackets", func(t *testing.T) {
+		t.Parallel()
+
+		p, err := ParsePasswordRules("required: [-]], lower")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.Classes[0].Chars != "-]"+LowerLetters {
+			t.Errorf("unexpected class %q", p.Classes[0].Chars)
+		}
+	})
+
+	t.Run("default_allowed", func(t *testing.T) {
+		t.Parallel()
+
+		p, err := ParsePasswordRules("minlength: 8")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(p.Classes) != 1 || len(p.Classes[0].Chars) != 95 {
+			t.Errorf("expected printable ASCII to be allowed, got %+v", p.Classes)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		for _, s := range []string{
+			"minlength",
+			"minlength: abc",
+			"required: emoji",
+			"allowed: [abc",
+			"color: blue",
+			"minlength: 10; maxlength: 8",
+		} {
+			if _, err := ParsePasswordRules(s); !errors.Is(err, ErrInvalidPasswordRules) {
+				t.Errorf("%q: %v should be %q", s, err, ErrInvalidPasswordRules)
+			}
+		}
+	})
+
+	t.Run("overlapping_required", func(t *testing.T) {
+		t.Parallel()
+
+		for _, rules := range []string{
+			"minlength: 8; required: upper, digit; required: digit",
+			"minlength: 8; required: digit; required: upper, digit",
+			"minlength: 8; required: digit; required: digit; allowed: upper",
+		} {
+			p, err := ParsePasswordRules(rules)
+			if err != nil {
+				t.Fatalf("%q: %v", rules, err)
+			}
+			if len(p.Classes) != 2 || p.Classes[0].Chars != Digits || p.Classes[1].Min != 0 {
+				t.Errorf("%q: unexpected classes %+v", rules, p.Classes)
+			}
+			for i := 0; i < N; i++ {
+				res, err := genRandom(r, p)
+				if err != nil {
+					t.Fatalf("%q: %v", rules, err)
+				}
+				if !strings.ContainsAny(res, Digits) || strings.Trim(res, UpperLetters+Digits) != "" {
+					t.Errorf("%q: unexpected password %q", rules, res)
+				}
+			}
+		}
+
+		// Partial overlaps keep the characters of the first class.
+		p, err := ParsePasswordRules("minlength: 4; required: [abc]; required: [cde]")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := genRandom(r, p); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("generate", func(t *testing.T) {
+		t.Parallel()
+
+		p, err := ParsePasswordRules("minlength: 20; required: upper; required: digit; allowed: [-_.!]; max-consecutive: 2;")
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.AllowRepeat = true
+		for i := 0; i < N; i++ {
+			res, err := genRandom(r, p.WithLength(8))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(res) != 20 {
+				t.Errorf("%q should have 20 characters", res)
+			}
+			if !strings.ContainsAny(res, UpperLetters) || !strings.ContainsAny(res, Digits) {
+				t.Errorf("%q should contain upper-case letters and digits", res)
+			}
+			if strings.ContainsAny(res, LowerLetters) {
+				t.Errorf("%q should not contain lower-case letters", res)
+			}
+		}
+	})
+}
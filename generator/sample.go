@@ -0,0 +1,135 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// samplerBufferSize is the size of the blocks a sampler reads. It is small
+// enough not to waste randomness on short passwords generated in batches.
+const samplerBufferSize = 512
+
+// sampler draws uniformly distributed values from a source of random
+// bytes. The source is buffered, so drawing many small values reads it in
+// blocks.
+type sampler struct {
+	r   *bufio.Reader
+	buf [8]byte
+}
+
+// newSampler returns a sampler reading random bytes from r.
+func newSampler(r io.Reader) *sampler {
+	return &sampler{r: bufio.NewReaderSize(r, samplerBufferSize)}
+}
+
+// uint64 returns a uniformly distributed uint64.
+func (s *sampler) uint64() (uint64, error) {
+	if _, err := io.ReadFull(s.r, s.buf[:]); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(s.buf[:]), nil
+}
+
+// intn returns a uniformly distributed int in [0, n). n must be positive.
+//
+// The values below 2^64 mod n are rejected, so that the remaining ones are
+// a multiple of n and the modulo introduces no bias.
+func (s *sampler) intn(n int) (int, error) {
+	bound := uint64(n)
+	threshold := -bound % bound
+	for {
+		v, err := s.uint64()
+		if err != nil {
+			return 0, err
+		}
+		if v >= threshold {
+			return int(v % bound), nil
+		}
+	}
+}
+
+// float64 returns a uniformly distributed float64 in [0, 1).
+func (s *sampler) float64() (float64, error) {
+	v, err := s.uint64()
+	if err != nil {
+		return 0, err
+	}
+	return float64(v>>11) / (1 << 53), nil
+}
+
+// sample returns k indexes drawn uniformly in [0, n), in random order.
+// k must not be negative. Without repeat, the indexes are distinct and k
+// must not exceed n.
+//
+// Distinct indexes are drawn with a partial Fisher–Yates shuffle of
+// [0, n): the first k steps of the shuffle are run on a sparse copy of the
+// slice holding only the moved positions, so the cost is O(k) whatever n.
+func (s *sampler) sample(n, k int, repeat bool) ([]int, error) {
+	if k < 0 {
+		return nil, fmt.Errorf("%w: %d", ErrNegativeLength, k)
+	}
+
+	result := make([]int, k)
+	if repeat {
+		for i := range result {
+			j, err := s.intn(n)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = j
+		}
+		return result, nil
+	}
+
+	if k > n {
+		return nil, ErrTooManyCharacters
+	}
+
+	moved := make(map[int]int, k)
+	at := func(i int) int {
+		if v, ok := moved[i]; ok {
+			return v
+		}
+		return i
+	}
+	for i := range result {
+		j, err := s.intn(n - i)
+		if err != nil {
+			return nil, err
+		}
+		j += i
+		result[i] = at(j)
+		moved[j] = at(i)
+	}
+
+	return result, nil
+}
+
+// shuffle shuffles the n elements swapped by swap with the Fisher–Yates
+// algorithm.
+func (s *sampler) shuffle(n int, swap func(i, j int)) error {
+	for i := n - 1; i > 0; i-- {
+		j, err := s.intn(i + 1)
+		if err != nil {
+			return err
+		}
+		swap(i, j)
+	}
+	return nil
+}
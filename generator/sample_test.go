@@ -0,0 +1,147 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func Test_samplerIntn(t *testing.T) {
+	t.Parallel()
+
+	t.Run("rejection", func(t *testing.T) {
+		t.Parallel()
+
+		// 2^64 mod 3 is 1: a draw of 0 is rejected, the next one is used.
+		b := make([]byte, 16)
+		binary.LittleEndian.PutUint64(b[8:], 5)
+		n, err := newSampler(bytes.NewReader(b)).intn(3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 2 {
+			t.Errorf("got %d, want 2", n)
+		}
+	})
+
+	t.Run("uniform", func(t *testing.T) {
+		t.Parallel()
+
+		const draws = 60000
+		s := newSampler(r)
+		counts := make([]int, 6)
+		for i := 0; i < draws; i++ {
+			n, err := s.intn(len(counts))
+			if err != nil {
+				t.Fatal(err)
+			}
+			counts[n]++
+		}
+		// Each count is within 5 standard deviations (about 456) of 10000.
+		for v, c := range counts {
+			if c < 9500 || c > 10500 {
+				t.Errorf("%d drawn %d times out of %d", v, c, draws)
+			}
+		}
+	})
+}
+
+func Test_samplerSample(t *testing.T) {
+	t.Parallel()
+
+	s := newSampler(r)
+	for _, k := range []int{0, 1, 10, 7775, 7776} {
+		indexes, err := s.sample(7776, k, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen := make(map[int]bool, k)
+		for _, i := range indexes {
+			if i < 0 || i >= 7776 || seen[i] {
+				t.Fatalf("k=%d: index %d is out of range or repeated", k, i)
+			}
+			seen[i] = true
+		}
+		if len(seen) != k {
+			t.Errorf("k=%d: got %d indexes", k, len(seen))
+		}
+	}
+
+	if _, err := s.sample(10, 11, false); !errors.Is(err, ErrTooManyCharacters) {
+		t.Errorf("%v should be %q", err, ErrTooManyCharacters)
+	}
+	for _, repeat := range []bool{false, true} {
+		if _, err := s.sample(10, -1, repeat); !errors.Is(err, ErrNegativeLength) {
+			t.Errorf("%v should be %q", err, ErrNegativeLength)
+		}
+	}
+	if indexes, err := s.sample(2, 100, true); err != nil || len(indexes) != 100 {
+		t.Errorf("got %d indexes, %v", len(indexes), err)
+	}
+}
+
+func Test_samplerShuffle(t *testing.T) {
+	t.Parallel()
+
+	s := newSampler(r)
+	x := []rune(LowerLetters)
+	if err := s.shuffle(len(x), func(i, j int) { x[i], x[j] = x[j], x[i] }); err != nil {
+		t.Fatal(err)
+	}
+	if len(x) != len(LowerLetters) || countIn(string(x), LowerLetters) != len(LowerLetters) || hasDuplicate(strings.Split(string(x), "")) {
+		t.Errorf("%q is not a permutation of %q", string(x), LowerLetters)
+	}
+}
+
+func Test_samplingLarge(t *testing.T) {
+	t.Parallel()
+
+	t.Run("all_words", func(t *testing.T) {
+		t.Parallel()
+
+		res, err := genHuman(r, HumanOptions{Words: len(EFFWords), Separator: " "})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hasDuplicate(strings.Split(res, " ")) {
+			t.Error("words should not be repeated")
+		}
+	})
+
+	t.Run("no_repeat_runes", func(t *testing.T) {
+		t.Parallel()
+
+		// 10000 CJK ideographs.
+		var chars strings.Builder
+		for ch := rune(0x4e00); ch < 0x4e00+10000; ch++ {
+			chars.WriteRune(ch)
+		}
+		res, err := genRandom(r, Policy{
+			MinLength: 10000,
+			Classes:   []CharClass{{Name: "cjk", Chars: chars.String()}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if utf8.RuneCountInString(res) != 10000 || hasDuplicate(strings.Split(res, "")) {
+			t.Error("every character should be used once")
+		}
+	})
+}
@@ -0,0 +1,68 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrRejected is the error returned when a screened generator keeps
+// generating rejected passwords.
+var ErrRejected = errors.New("every generated password was rejected")
+
+// maxScreenAttempts is the number of passwords a screened generator
+// generates before it gives up.
+const maxScreenAttempts = 100
+
+// Screened is a generator regenerating the passwords rejected by a screen,
+// e.g. passwords known to be breached.
+type Screened struct {
+	g      Generator
+	reject func(string) (bool, error)
+}
+
+// NewScreened returns a generator returning the passwords of g for which
+// reject returns false. reject must be safe for concurrent use to generate
+// batches.
+func NewScreened(g Generator, reject func(pass string) (bool, error)) *Screened {
+	return &Screened{g: g, reject: reject}
+}
+
+// Generate implements Generator.
+func (s *Screened) Generate(r io.Reader) (string, error) {
+	for attempt := 0; attempt < maxScreenAttempts; attempt++ {
+		pass, err := s.g.Generate(r)
+		if err != nil {
+			return "", err
+		}
+		rejected, err := s.reject(pass)
+		if err != nil {
+			return "", err
+		}
+		if !rejected {
+			return pass, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %d attempts", ErrRejected, maxScreenAttempts)
+}
+
+// Entropy returns the entropy of the underlying generator. It slightly
+// overestimates the entropy of the screened passwords, as long as the
+// screen rejects few of them.
+func (s *Screened) Entropy() (float64, error) {
+	return s.g.Entropy()
+}
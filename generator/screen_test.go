@@ -0,0 +1,61 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestScreened(t *testing.T) {
+	t.Parallel()
+
+	t.Run("reject", func(t *testing.T) {
+		t.Parallel()
+
+		g := NewScreened(NewPIN(PINOptions{Length: 1}), func(pass string) (bool, error) {
+			return strings.ContainsAny(pass, "01234"), nil
+		})
+		for i := 0; i < N; i++ {
+			res, err := g.Generate(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.ContainsAny(res, "01234") {
+				t.Fatalf("%q should have been rejected", res)
+			}
+		}
+	})
+
+	t.Run("reject_all", func(t *testing.T) {
+		t.Parallel()
+
+		g := NewScreened(NewPIN(PINOptions{Length: 4}), func(string) (bool, error) { return true, nil })
+		if _, err := g.Generate(r); !errors.Is(err, ErrRejected) {
+			t.Errorf("%v should be %q", err, ErrRejected)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		errScreen := errors.New("screen error")
+		g := NewScreened(NewPIN(PINOptions{Length: 4}), func(string) (bool, error) { return false, errScreen })
+		if _, err := g.Generate(r); !errors.Is(err, errScreen) {
+			t.Errorf("%v should be %q", err, errScreen)
+		}
+	})
+}
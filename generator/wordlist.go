@@ -0,0 +1,243 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"bufio"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+)
+
+// ErrInvalidWordlist is the error returned when a wordlist cannot be used.
+var ErrInvalidWordlist = errors.New("invalid wordlist")
+
+// minWordlistSize is the size under which loading a wordlist warns: it is
+// the size of EFF's short wordlists, about 10.3 bits per word.
+const minWordlistSize = 1296
+
+//go:embed wordlists/*.txt
+var wordlistsFS embed.FS
+
+// Built-in wordlists, in the diceware numbering of their publishers.
+var (
+	// WordlistEFFLarge is EFF's large wordlist, 7776 words.
+	// <https://www.eff.org/files/2016/07/18/eff_large_wordlist.txt>
+	WordlistEFFLarge = mustLoadWordlist("eff-large", language.English, "wordlists/eff_large.txt")
+
+	// WordlistEFFShort1 is EFF's first short wordlist, 1296 words of 3 to
+	// 5 letters, quicker to type.
+	// <https://www.eff.org/files/2016/09/08/eff_short_wordlist_1.txt>
+	WordlistEFFShort1 = mustLoadWordlist("eff-short-1", language.English, "wordlists/eff_short_1.txt")
+
+	// WordlistEFFShort2 is EFF's second short wordlist, 1296 words with
+	// unique three-character prefixes.
+	// <https://www.eff.org/files/2016/09/08/eff_short_wordlist_2_0.txt>
+	WordlistEFFShort2 = mustLoadWordlist("eff-short-2", language.English, "wordlists/eff_short_2.txt")
+
+	// WordlistDiceware is the original diceware wordlist of Arnold G.
+	// Reinhold, 7776 words. <https://theworld.com/~reinhold/diceware.html>
+	WordlistDiceware = mustLoadWordlist("diceware", language.English, "wordlists/diceware.txt")
+)
+
+// Built-in wordlists in other languages than English, from the BIP39
+// specification. They have 2048 words each and no diceware numbering.
+// <https://github.com/bitcoin/bips/tree/master/bip-0039>
+var (
+	WordlistBIP39Czech              = mustLoadWordlist("bip39-czech", language.Czech, "wordlists/bip39_czech.txt")
+	WordlistBIP39French             = mustLoadWordlist("bip39-french", language.French, "wordlists/bip39_french.txt")
+	WordlistBIP39Italian            = mustLoadWordlist("bip39-italian", language.Italian, "wordlists/bip39_italian.txt")
+	WordlistBIP39Japanese           = mustLoadWordlist("bip39-japanese", language.Japanese, "wordlists/bip39_japanese.txt")
+	WordlistBIP39Korean             = mustLoadWordlist("bip39-korean", language.Korean, "wordlists/bip39_korean.txt")
+	WordlistBIP39Spanish            = mustLoadWordlist("bip39-spanish", language.Spanish, "wordlists/bip39_spanish.txt")
+	WordlistBIP39ChineseSimplified  = mustLoadWordlist("bip39-chinese-simplified", language.SimplifiedChinese, "wordlists/bip39_chinese_simplified.txt")
+	WordlistBIP39ChineseTraditional = mustLoadWordlist("bip39-chinese-traditional", language.TraditionalChinese, "wordlists/bip39_chinese_traditional.txt")
+)
+
+// Built-in wordlists of languages without a BIP39 list, compiled for
+// pwgenie from common words. They have 1296 words each, numbered for four
+// dice like EFF's short lists.
+var (
+	// WordlistGerman has short German nouns, verbs and adjectives without
+	// two words differing only by their umlauts, so that -ascii keeps
+	// them all.
+	WordlistGerman = mustLoadWordlist("german", language.German, "wordlists/german.txt")
+
+	// WordlistVietnamese has Vietnamese syllables. Many differ only by
+	// their tone marks: without diacritics, 782 of them remain.
+	WordlistVietnamese = mustLoadWordlist("vietnamese", language.Vietnamese, "wordlists/vietnamese.txt")
+)
+
+// Wordlist is a list of unique words human-friendly passwords are made of.
+type Wordlist struct {
+	// Name identifies the wordlist, e.g. "eff-large".
+	Name string
+	// Language is the language of the words, used to capitalize them.
+	// It is language.Und when unknown.
+	Language language.Tag
+	// Words are the words of the list, in diceware order when the list
+	// has a diceware numbering.
+	Words []string
+	// Warnings are the problems found while loading the list which do not
+	// prevent using it, e.g. duplicate words.
+	Warnings []string
+}
+
+// Wordlists returns the built-in wordlists.
+func Wordlists() []*Wordlist {
+	return []*Wordlist{
+		WordlistEFFLarge, WordlistEFFShort1, WordlistEFFShort2, WordlistDiceware,
+		WordlistBIP39Czech, WordlistBIP39French, WordlistBIP39Italian,
+		WordlistBIP39Japanese, WordlistBIP39Korean, WordlistBIP39Spanish,
+		WordlistBIP39ChineseSimplified, WordlistBIP39ChineseTraditional,
+		WordlistGerman, WordlistVietnamese,
+	}
+}
+
+// LookupWordlist returns the built-in wordlist with the given name.
+func LookupWordlist(name string) (*Wordlist, bool) {
+	for _, w := range Wordlists() {
+		if w.Name == name {
+			return w, true
+		}
+	}
+	return nil, false
+}
+
+// LoadWordlist reads a wordlist from a file. See ParseWordlist for the
+// format.
+func LoadWordlist(path string) (*Wordlist, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseWordlist(path, f)
+}
+
+// dicewareLineRx matches a line of a diceware-numbered wordlist, e.g.
+// "11111	abacus".
+var dicewareLineRx = regexp.MustCompile(`^([1-6]+)\s+(\S.*)$`)
+
+// ParseWordlist reads a wordlist with one word per line. Lines may be
+// numbered with dice rolls as in diceware lists ("11111 abacus"), in
+// which case the words are sorted by their numbers. Words are normalized
+// to Unicode NFC. Empty lines are rejected; duplicate words are removed
+// with a warning, as is a list smaller than EFF's short lists.
+func ParseWordlist(name string, r io.Reader) (*Wordlist, error) {
+	type entry struct {
+		roll string
+		word string
+	}
+
+	var (
+		entries  []entry
+		numbered int
+		scanner  = bufio.NewScanner(r)
+		line     int
+	)
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			return nil, fmt.Errorf("%w: %s: line %d is empty", ErrInvalidWordlist, name, line)
+		}
+
+		text = norm.NFC.String(text)
+		e := entry{word: text}
+		if m := dicewareLineRx.FindStringSubmatch(text); m != nil {
+			e = entry{roll: m[1], word: m[2]}
+			numbered++
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if numbered == len(entries) {
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].roll < entries[j].roll })
+	} else if numbered > 0 {
+		// A word list that happens to contain dice numbers, e.g. "1234"
+		// in a list of words: keep the lines as they are.
+		for i := range entries {
+			if entries[i].roll != "" {
+				entries[i].word = entries[i].roll + " " + entries[i].word
+			}
+		}
+	}
+
+	words := make([]string, len(entries))
+	for i, e := range entries {
+		words[i] = e.word
+	}
+
+	return newWordlist(name, language.Und, words)
+}
+
+// newWordlist returns the wordlist of the given words, without their
+// duplicates, and the warnings about them.
+func newWordlist(name string, lang language.Tag, words []string) (*Wordlist, error) {
+	w := &Wordlist{Name: name, Language: lang}
+	seen := make(map[string]bool, len(words))
+	var duplicates int
+	for _, word := range words {
+		if seen[word] {
+			duplicates++
+			continue
+		}
+		seen[word] = true
+		w.Words = append(w.Words, word)
+	}
+
+	if len(w.Words) < 2 {
+		return nil, fmt.Errorf("%w: %s: at least 2 distinct words are required, got %d", ErrInvalidWordlist, name, len(w.Words))
+	}
+	if duplicates > 0 {
+		w.Warnings = append(w.Warnings, fmt.Sprintf("%s: removed %d duplicate words", name, duplicates))
+	}
+	if len(w.Words) < minWordlistSize {
+		w.Warnings = append(w.Warnings, fmt.Sprintf("%s: only %d words (%.1f bits per word), at least %d are recommended",
+			name, len(w.Words), math.Log2(float64(len(w.Words))), minWordlistSize))
+	}
+
+	return w, nil
+}
+
+// mustLoadWordlist loads an embedded wordlist.
+func mustLoadWordlist(name string, lang language.Tag, path string) *Wordlist {
+	f, err := wordlistsFS.Open(path)
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+
+	w, err := ParseWordlist(name, f)
+	if err != nil {
+		panic(err)
+	}
+	w.Language = lang
+	return w
+}
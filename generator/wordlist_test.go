@@ -0,0 +1,215 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestWordlists(t *testing.T) {
+	t.Parallel()
+
+	sizes := map[string]int{
+		"eff-large":   7776,
+		"eff-short-1": 1296,
+		"eff-short-2": 1296,
+		"diceware":    7776,
+
+		"bip39-czech":               2048,
+		"bip39-french":              2048,
+		"bip39-italian":             2048,
+		"bip39-japanese":            2048,
+		"bip39-korean":              2048,
+		"bip39-spanish":             2048,
+		"bip39-chinese-simplified":  2048,
+		"bip39-chinese-traditional": 2048,
+
+		"german":     1296,
+		"vietnamese": 1296,
+	}
+	for name, size := range sizes {
+		w, ok := LookupWordlist(name)
+		if !ok {
+			t.Fatalf("%s: built-in wordlist not found", name)
+		}
+		if len(w.Words) != size {
+			t.Errorf("%s: got %d words, want %d", name, len(w.Words), size)
+		}
+		if len(w.Warnings) != 0 {
+			t.Errorf("%s: unexpected warnings %q", name, w.Warnings)
+		}
+	}
+
+	if EFFWords[0] != "abacus" || EFFWords[len(EFFWords)-1] != "zoom" {
+		t.Errorf("EFFWords is not in diceware order")
+	}
+}
+
+func TestParseWordlist(t *testing.T) {
+	t.Parallel()
+
+	t.Run("plain", func(t *testing.T) {
+		t.Parallel()
+
+		w, err := ParseWordlist("plain", strings.NewReader("alpha\nbravo\r\ncharlie\nalpha\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(w.Words, " ") != "alpha bravo charlie" {
+			t.Errorf("unexpected words %q", w.Words)
+		}
+		if len(w.Warnings) != 2 {
+			t.Errorf("expected duplicate and size warnings, got %q", w.Warnings)
+		}
+	})
+
+	t.Run("numbered", func(t *testing.T) {
+		t.Parallel()
+
+		w, err := ParseWordlist("numbered", strings.NewReader("12\tbravo\n11\talpha\n13 charlie\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(w.Words, " ") != "alpha bravo charlie" {
+			t.Errorf("unexpected words %q", w.Words)
+		}
+	})
+
+	t.Run("nfc", func(t *testing.T) {
+		t.Parallel()
+
+		// "élève" decomposed, then composed.
+		w, err := ParseWordlist("nfc", strings.NewReader("e\u0301le\u0300ve\n\u00e9l\u00e8ve\nabri\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(w.Words, " ") != "\u00e9l\u00e8ve abri" {
+			t.Errorf("unexpected words %+q", w.Words)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+
+		for _, s := range []string{"alpha\n\nbravo\n", "alpha\nalpha\n", ""} {
+			if _, err := ParseWordlist("invalid", strings.NewReader(s)); !errors.Is(err, ErrInvalidWordlist) {
+				t.Errorf("%q: %v should be %q", s, err, ErrInvalidWordlist)
+			}
+		}
+	})
+}
+
+func Test_genHumanWordlist(t *testing.T) {
+	t.Parallel()
+
+	w, err := ParseWordlist("custom", strings.NewReader("alpha\nbravo\ncharlie\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := genHuman(r, HumanOptions{Words: 3, Separator: " ", Wordlist: w})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, word := range strings.Split(res, " ") {
+		if word != "alpha" && word != "bravo" && word != "charlie" {
+			t.Errorf("%q is not from the wordlist", word)
+		}
+	}
+
+	if _, err := genHuman(r, HumanOptions{Words: 4, Wordlist: w}); !errors.Is(err, ErrTooManyCharacters) {
+		t.Errorf("%v should be %q", err, ErrTooManyCharacters)
+	}
+}
+
+func TestWordlistForLanguage(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]*Wordlist{
+		"en":    WordlistEFFLarge,
+		"en-GB": WordlistEFFLarge,
+		"fr":    WordlistBIP39French,
+		"fr-CA": WordlistBIP39French,
+		"es":    WordlistBIP39Spanish,
+		"ja":    WordlistBIP39Japanese,
+		"zh":    WordlistBIP39ChineseSimplified,
+		"zh-TW": WordlistBIP39ChineseTraditional,
+		"de":    WordlistGerman,
+		"de-AT": WordlistGerman,
+		"vi":    WordlistVietnamese,
+	}
+	for lang, want := range tests {
+		if got, ok := WordlistForLanguage(language.MustParse(lang)); !ok || got != want {
+			t.Errorf("%s: got %v, want %s", lang, got, want.Name)
+		}
+	}
+
+	if w, ok := WordlistForLanguage(language.Thai); ok {
+		t.Errorf("th: unexpected wordlist %s", w.Name)
+	}
+}
+
+func TestWordlistASCII(t *testing.T) {
+	t.Parallel()
+
+	w, err := ParseWordlist("vi", strings.NewReader("đường\nduong\nphở\nStraße\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ascii, err := w.ASCII()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(ascii.Words, " ") != "duong pho Strasse" {
+		t.Errorf("unexpected words %q", ascii.Words)
+	}
+	if len(ascii.Warnings) != 2 {
+		t.Errorf("expected duplicate and size warnings, got %q", ascii.Warnings)
+	}
+
+	for _, w := range []*Wordlist{WordlistBIP39French, WordlistBIP39Spanish, WordlistBIP39Czech, WordlistVietnamese} {
+		if _, err := w.ASCII(); err != nil {
+			t.Errorf("%s: %v", w.Name, err)
+		}
+	}
+	if ascii, err := WordlistGerman.ASCII(); err != nil || len(ascii.Words) != len(WordlistGerman.Words) {
+		t.Errorf("german: the words should remain distinct in ASCII, got %v", err)
+	}
+	if _, err := WordlistBIP39Japanese.ASCII(); !errors.Is(err, ErrInvalidWordlist) {
+		t.Errorf("%v should be %q", err, ErrInvalidWordlist)
+	}
+}
+
+func Test_formatWordsLanguage(t *testing.T) {
+	t.Parallel()
+
+	w, err := ParseWordlist("nl", strings.NewReader("ijsvogel\nijzer\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := HumanOptions{Separator: " ", Capitalize: true, Wordlist: w}
+
+	if got := formatWords(w.Words, opts); got != "Ijsvogel Ijzer" {
+		t.Errorf("und: got %q", got)
+	}
+	w.Language = language.Dutch
+	if got := formatWords(w.Words, opts); got != "IJsvogel IJzer" {
+		t.Errorf("nl: got %q", got)
+	}
+}
@@ -3,7 +3,11 @@ module github.com/ntk148v/pwgenie
 go 1.20
 
 require (
+	github.com/BurntSushi/toml v1.3.2
 	github.com/atotto/clipboard v0.1.4
-	golang.org/x/exp v0.0.0-20230425010034-47ecfdc1ba53
+	golang.org/x/crypto v0.21.0
+	golang.org/x/term v0.18.0
 	golang.org/x/text v0.22.0
 )
+
+require golang.org/x/sys v0.18.0 // indirect
@@ -17,19 +17,23 @@ package main
 
 import (
 	"crypto/rand"
-	"errors"
 	"flag"
 	"fmt"
 	"io"
-	"math"
-	"math/big"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
-	"github.com/atotto/clipboard"
-	"golang.org/x/exp/slices"
-	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
+
+	"github.com/ntk148v/pwgenie/breach"
+	"github.com/ntk148v/pwgenie/crypt"
+	"github.com/ntk148v/pwgenie/generator"
+	"github.com/ntk148v/pwgenie/strength"
 )
 
 func printHelp() {
@@ -48,15 +52,69 @@ Options
   -allow-repeat
 		Allow repeat characters in the generated password
 
+  -check-breached string
+		Regenerate the passwords found in this Have I Been Pwned SHA-1 or NTLM hash file
+
+  -clear-after duration
+		Clear the clipboard after this time, e.g. 30s, if it still holds the password
+
+  -clipboard-backend string
+		The clipboard the password is copied to: auto, osc52, system, tmux,
+		wayland, wayland-primary, x11 or x11-primary (default "auto")
+
+  -config string
+		The configuration file (default: $PWGENIE_CONFIG, or pwgenie/config.toml
+		in the user configuration directory, e.g. $XDG_CONFIG_HOME)
+
+  -count int
+		The number of passwords to generate (default 1)
+
+  -format string
+		The output format: text, json, csv or ndjson, the structured formats
+		including the parameters, entropy and time of each password (default "text")
+
+  -hash string
+		Print the hash of each password next to it, in the modular crypt format of
+		bcrypt, argon2id, scrypt, sha512crypt or pbkdf2-sha256
+
+  -hash-copy
+		With -hash-only, copy the password to the clipboard, which is skipped
+		otherwise
+
+  -hash-only
+		Print the hash of each password instead of the password with -hash
+
   -no-clipboard
 		Disable automatic copying of generated password to clipboard
 
+  -preset string
+		Generate passwords meeting this compliance preset, e.g. pci-dss-4,
+		listed with 'pwgenie presets'
+
+  -profile string
+		Run the subcommand and options of this profile of the configuration file
+
+  -show-entropy
+		Print the entropy of the generated password to stderr
+
+  -unique
+		Guarantee that the passwords generated with -count are distinct
+
+  -workers int
+		The number of passwords generated in parallel with -count (default: the number of CPUs)
+
 Subcommands
 -----------
 
   human    Generate a human-friendly memorable password
   random   Generate a random password with specified complexity
   pin      Generate a random numeric PIN code
+  derive   Derive the password of a site from a master password, like LessPass
+  check    Estimate the strength of a password read from stdin
+  breached Look up a password read from stdin in a Have I Been Pwned hash file
+  serve    Serve the generators over an HTTP/JSON API
+  daemon   Serve the generators over a Unix domain socket
+  presets  List the compliance presets and their rationale
 
 Run subcommand with '-h' for subcommand's options.
 
@@ -74,6 +132,12 @@ Example
 
   $ pwgenie random -symb -num -upper
   _U*HkTzA
+
+  $ pwgenie -preset pci-dss-4 random
+  rXk7GdQ2mWbT
+
+  $ pwgenie -hash sha512crypt -hash-only random -length 16
+  $6$rounds=656000$kq1WcH3aEzZ1pRcV$...
 `
 	fmt.Fprintln(os.Stderr, helpText)
 	os.Exit(0)
@@ -84,16 +148,56 @@ func exitOnError(msg string) {
 	os.Exit(1)
 }
 
-// ErrTooManyCharacters is the error returned with the number of letters
-// exceeds the number of available letters and repeats are not allowed.
-var ErrTooManyCharacters = errors.New("number of characters exceeds available letters and repeats are not allowed")
-
 func main() {
 	allowRepeat := flag.Bool("allow-repeat", false, "Allow repeat characters in the generated password")
 	noClipboard := flag.Bool("no-clipboard", false, "Disable automatic copying of generated password to clipboard")
+	showEntropy := flag.Bool("show-entropy", false, "Print the entropy of the generated password to stderr")
+	clearAfter := flag.Duration("clear-after", 0, "Clear the clipboard after this time, e.g. 30s, if it still holds the password")
+	clipboardBackend := flag.String("clipboard-backend", "auto", "The clipboard the password is copied to: "+strings.Join(clipboardBackendNames(), ", "))
+	checkBreached := flag.String("check-breached", "", "Regenerate the passwords found in this Have I Been Pwned SHA-1 or NTLM hash file")
+	count := flag.Int("count", 1, "The number of passwords to generate")
+	unique := flag.Bool("unique", false, "Guarantee that the passwords generated with -count are distinct")
+	format := flag.String("format", "text", "The output format: "+strings.Join(formats, ", ")+"; the structured formats include the parameters, entropy and time of each password")
+	workers := flag.Int("workers", 0, "The number of passwords generated in parallel with -count (default: the number of CPUs)")
+	configFile := flag.String("config", "", "The configuration file (default: $PWGENIE_CONFIG, or pwgenie/config.toml in the user configuration directory, e.g. $XDG_CONFIG_HOME)")
+	profileName := flag.String("profile", "", "Run the subcommand and options of this profile of the configuration file")
+	hashAlg := flag.String("hash", "", "Print the hash of each password next to it, in the modular crypt format of "+algorithmNames())
+	hashOnly := flag.Bool("hash-only", false, "Print the hash of each password instead of the password with -hash")
+	hashCopy := flag.Bool("hash-copy", false, "With -hash-only, copy the password to the clipboard, which is skipped otherwise")
+	presetName := flag.String("preset", "", "Generate passwords meeting this compliance preset: "+strings.Join(presetNames(), ", ")+", listed with 'pwgenie presets'")
 	flag.Usage = printHelp
 	flag.Parse()
 
+	// Command-line flags override environment variables, which override
+	// the configuration file
+	cfg, err := loadConfig(configPath(*configFile))
+	if err != nil {
+		exitOnError(err.Error())
+	}
+	if err := applyDefaults(flag.CommandLine, cfg); err != nil {
+		exitOnError(err.Error())
+	}
+
+	cb, err := newClipboard(*clipboardBackend)
+	if err != nil {
+		exitOnError(err.Error())
+	}
+	preset, err := lookupPreset(*presetName)
+	if err != nil {
+		exitOnError(err.Error())
+	}
+	var alg crypt.Algorithm
+	if *hashAlg != "" {
+		if alg, err = crypt.ParseAlgorithm(*hashAlg); err != nil {
+			exitOnError(fmt.Sprintf("%v, want one of %s", err, algorithmNames()))
+		}
+	} else if *hashOnly {
+		exitOnError("-hash-only requires -hash")
+	}
+	if *hashCopy && !*hashOnly {
+		exitOnError("-hash-copy requires -hash-only")
+	}
+
 	// Memorable password
 	human := flag.NewFlagSet("human", flag.ExitOnError)
 	human.Usage = func() {
@@ -104,6 +208,11 @@ func main() {
 	words := human.Int("words", 5, "The number of words in the generated password")
 	separator := human.String("sep", " ", "The separator for words in the generated password")
 	capitalize := human.Bool("cap", false, "Enable capitalization of each word in the generated password")
+	wordlist := human.String("wordlist", "eff-large", "The wordlist to pick words from: a built-in list ("+strings.Join(wordlistNames(), ", ")+") or the path of a file with one word per line")
+	lang := human.String("lang", "", "The language of the words: picks the built-in wordlist of the language ("+strings.Join(languageNames(), ", ")+"), or sets the language of -wordlist for capitalization")
+	ascii := human.Bool("ascii", false, "Transliterate the words to ASCII, e.g. without diacritics")
+	dice := human.Bool("dice", false, "Pick words from physical dice rolls typed on stdin instead of the random source")
+	humanBits := human.Float64("bits", 0, "Pick the smallest number of words reaching this entropy in bits, instead of -words")
 
 	// Random
 	random := flag.NewFlagSet("random", flag.ExitOnError)
@@ -111,6 +220,37 @@ func main() {
 	hasUpper := random.Bool("upper", false, "Enable the inclusion of upper-case letters in the generated passwords")
 	hasDigits := random.Bool("digit", false, "Enable the inclusion of numbers in the generated password")
 	hasSymbols := random.Bool("symbol", false, "Enable the inclusion of symbols in the generated password")
+	classes := []classFlags{
+		{
+			name: "lower", chars: generator.LowerLetters,
+			min: random.Int("min-lower", 0, "The minimum number of lower-case letters in the generated password"),
+			max: random.Int("max-lower", 0, "The maximum number of lower-case letters in the generated password (0 means no limit)"),
+		},
+		{
+			name: "upper", chars: generator.UpperLetters, enabled: hasUpper,
+			min: random.Int("min-upper", 0, "The minimum number of upper-case letters in the generated password"),
+			max: random.Int("max-upper", 0, "The maximum number of upper-case letters in the generated password (0 means no limit)"),
+		},
+		{
+			name: "digit", chars: generator.Digits, enabled: hasDigits,
+			min: random.Int("min-digit", 0, "The minimum number of digits in the generated password"),
+			max: random.Int("max-digit", 0, "The maximum number of digits in the generated password (0 means no limit)"),
+		},
+		{
+			name: "symbol", chars: generator.Symbols, enabled: hasSymbols,
+			min: random.Int("min-symbol", 0, "The minimum number of symbols in the generated password"),
+			max: random.Int("max-symbol", 0, "The maximum number of symbols in the generated password (0 means no limit)"),
+		},
+	}
+	charset := random.String("charset", "", "The characters the generated password is mostly made of, instead of lower-case letters, e.g. a localized alphabet")
+	symbolSet := random.String("symbols", "", "The symbols included in the generated password, instead of "+generator.Symbols+" (implies -symbol)")
+	randomExclude := random.String("exclude", "", "Never include these characters in the generated password, before the characters of each class are counted")
+	randomAmbiguous := random.Bool("exclude-ambiguous", false, "Exclude look-alike characters ("+generator.Ambiguous+") from the generated password")
+	allowed := random.String("allowed", "", "Restrict the generated password to these characters")
+	forbidden := random.String("forbidden", "", "Never include these characters in the generated password")
+	maxConsecutive := random.Int("max-consecutive", 0, "The maximum number of consecutive identical characters (0 means no limit)")
+	randomBits := random.Float64("bits", 0, "Pick the smallest length reaching this entropy in bits, instead of -length")
+	rules := random.String("rules", "", "Generate a password satisfying the given passwordrules string, e.g. 'minlength: 20; required: upper; required: digit;'")
 	random.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Generate a random password with specified complexity\n\n")
 		fmt.Fprintf(os.Stderr, "Usage of '%s random':\n", os.Args[0])
@@ -125,259 +265,582 @@ func main() {
 		pin.PrintDefaults()
 	}
 	lenNums := pin.Int("length", 6, "The number of digits in the generated PIN code")
+	pinExclude := pin.String("exclude", "", "Never include these digits in the generated PIN code")
+	pinAmbiguous := pin.Bool("exclude-ambiguous", false, "Exclude look-alike digits (0 and 1) from the generated PIN code")
+	pinStrong := pin.Bool("strong", false, "Avoid weak patterns: runs, repeated blocks, keypad lines, dates and years, and report the entropy left")
+	pinAvoid := pin.String("avoid", "", "Avoid these weak patterns only, a comma-separated list of runs, repeats, keypad, dates and years (implies -strong)")
+	pinBits := pin.Float64("bits", 0, "Pick the smallest number of digits reaching this entropy in bits, instead of -length")
+
+	// Derive
+	derive := flag.NewFlagSet("derive", flag.ExitOnError)
+	derive.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Derive the password of a site from a master password read from stdin, like LessPass\n\n")
+		fmt.Fprintf(os.Stderr, "Usage of '%s derive':\n", os.Args[0])
+		derive.PrintDefaults()
+	}
+	site := derive.String("site", "", "The site the password is for, e.g. example.org")
+	login := derive.String("login", "", "The login on the site")
+	counter := derive.Int("counter", 1, "The counter, incremented to change the password of the site")
+	lenDerived := derive.Int("length", 16, "The number of characters in the derived password")
+	deriveLower := derive.Bool("lower", true, "Include lower-case letters in the derived password")
+	deriveUpper := derive.Bool("upper", true, "Include upper-case letters in the derived password")
+	deriveDigits := derive.Bool("digit", true, "Include digits in the derived password")
+	deriveSymbols := derive.Bool("symbol", true, "Include symbols ("+generator.LessPassSymbols+") in the derived password")
+
+	// Check
+	check := flag.NewFlagSet("check", flag.ExitOnError)
+	check.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Estimate the strength of a password read from stdin\n\n")
+		fmt.Fprintf(os.Stderr, "Usage of '%s check':\n", os.Args[0])
+		check.PrintDefaults()
+	}
+
+	// Breached
+	breached := flag.NewFlagSet("breached", flag.ExitOnError)
+	breached.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Look up a password read from stdin in a Have I Been Pwned hash file\n\n")
+		fmt.Fprintf(os.Stderr, "Usage of '%s breached':\n", os.Args[0])
+		breached.PrintDefaults()
+	}
+	hashFile := breached.String("file", "", "The sorted Have I Been Pwned SHA-1 or NTLM hash file (default: the file of -check-breached)")
+
+	// Serve
+	serve := flag.NewFlagSet("serve", flag.ExitOnError)
+	serve.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Serve the generators over an HTTP/JSON API\n\n")
+		fmt.Fprintf(os.Stderr, "Usage of '%s serve':\n", os.Args[0])
+		serve.PrintDefaults()
+	}
+	addr := serve.String("addr", "127.0.0.1:8080", "The address to listen on")
+	token := serve.String("token", "", "Require this bearer token in the Authorization header (default: $PWGENIE_TOKEN)")
+	rateLimit := serve.Float64("rate", 10, "The number of requests per second allowed to each client (0 means no limit)")
+	burst := serve.Int("burst", 20, "The number of requests a client may make at once before -rate applies")
+
+	// Daemon
+	daemonCmd := flag.NewFlagSet("daemon", flag.ExitOnError)
+	daemonCmd.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Serve the generators over a Unix domain socket\n\n")
+		fmt.Fprintf(os.Stderr, "Usage of '%s daemon':\n", os.Args[0])
+		daemonCmd.PrintDefaults()
+	}
+	socket := daemonCmd.String("socket", "", "The path of the Unix domain socket to listen on")
+	socketMode := daemonCmd.String("mode", "0600", "The permissions of the socket, in octal: who may connect to the daemon")
+	socketGroup := daemonCmd.String("group", "", "The group owning the socket, e.g. to let its members connect with -mode 0660")
+
+	// Presets
+	presets := flag.NewFlagSet("presets", flag.ExitOnError)
+	presets.Usage = func() {
+		fmt.Fprintf(os.Stderr, "List the compliance presets and their rationale\n\n")
+		fmt.Fprintf(os.Stderr, "Usage of '%s presets':\n", os.Args[0])
+		presets.PrintDefaults()
+	}
 
-	if len(os.Args) < 2 {
+	args := flag.Args()
+	if *profileName != "" {
+		p, ok := cfg.Profiles[*profileName]
+		if !ok {
+			exitOnError(fmt.Sprintf("unknown profile %q", *profileName))
+		}
+		if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+			exitOnError(fmt.Sprintf("profile %s runs %s, give only its options, after --", *profileName, p.Command))
+		}
+		args = p.args(args)
+	}
+	if len(args) == 0 {
 		printHelp()
 	}
 
-	r := rand.Reader
+	var r io.Reader = rand.Reader
+
+	if !validFormat(*format) {
+		exitOnError(fmt.Sprintf("unknown format %q, want one of %s", *format, strings.Join(formats, ", ")))
+	}
 
 	var (
-		pass string
-		err  error
+		gen    generator.Generator
+		batch  []string
+		params recordParams
 	)
 
-	args := flag.Args()
 	switch args[0] {
 	case "human":
-		_ = human.Parse(args[1:])
-		pass, err = genHuman(r, *words, *separator, *capitalize, *allowRepeat)
+		parseSubcommand(human, args[1:])
+		if preset != nil {
+			if err := checkPreset(preset, human, "words", "sep", "cap", "dice", "bits"); err != nil {
+				exitOnError(err.Error())
+			}
+			if *words, err = presetBounds(preset, human, "words", *words, preset.Human.Words, 0); err != nil {
+				exitOnError(err.Error())
+			}
+		}
+		var custom bool
+		human.Visit(func(f *flag.Flag) { custom = custom || f.Name == "wordlist" })
+		list, err := humanWordlist(*wordlist, custom, *lang, *ascii)
+		if err != nil {
+			exitOnError(err.Error())
+		}
+		opts := generator.HumanOptions{
+			Words:       *words,
+			Separator:   *separator,
+			Capitalize:  *capitalize,
+			AllowRepeat: *allowRepeat,
+			Wordlist:    list,
+		}
+		if *humanBits > 0 {
+			opts.Words, err = generator.ForEntropy(*humanBits, func(n int) generator.Generator {
+				o := opts
+				o.Words = n
+				return generator.NewHuman(o)
+			})
+			if err != nil {
+				exitOnError(err.Error())
+			}
+			if preset != nil && opts.Words < preset.Human.Words {
+				opts.Words = preset.Human.Words
+			}
+		}
+		gen = generator.NewHuman(opts)
+		params = humanParams(opts, *dice)
+		if *dice {
+			if *count > 1 {
+				exitOnError("-count cannot be used with -dice")
+			}
+			gen = generator.NewDice(opts)
+			r, err = diceReader(opts)
+			if err != nil {
+				exitOnError(err.Error())
+			}
+		}
 	case "random":
-		_ = random.Parse(args[1:])
-		pass, err = genRandom(r, *lenChars, *hasUpper, *hasDigits, *hasSymbols, *allowRepeat)
+		parseSubcommand(random, args[1:])
+		if preset != nil {
+			if err := checkPreset(preset, random, "length", "bits"); err != nil {
+				exitOnError(err.Error())
+			}
+			if *lenChars, err = presetBounds(preset, random, "length", *lenChars, preset.Random.MinLength, preset.Random.MaxLength); err != nil {
+				exitOnError(err.Error())
+			}
+		}
+		if *charset != "" {
+			classes[0].chars = *charset
+		}
+		if *symbolSet != "" {
+			*hasSymbols = true
+			classes[3].chars = *symbolSet
+		}
+		var rulesPolicy *generator.Policy
+		if *rules != "" {
+			if err := checkRules(random); err != nil {
+				exitOnError(err.Error())
+			}
+			p, err := generator.ParsePasswordRules(*rules)
+			if err != nil {
+				exitOnError(err.Error())
+			}
+			p.AllowRepeat = *allowRepeat
+			rulesPolicy = &p
+		}
+
+		// newPolicy returns the random policy for the given length.
+		newPolicy := func(length int) generator.Policy {
+			if preset != nil {
+				policy := preset.Random.WithLength(length)
+				policy.AllowRepeat = *allowRepeat
+				return policy
+			}
+			policy := generator.RandomOptions{
+				Length:      length,
+				Upper:       *hasUpper,
+				Digits:      *hasDigits,
+				Symbols:     *hasSymbols,
+				AllowRepeat: *allowRepeat,
+				Charset:     *charset,
+				SymbolSet:   *symbolSet,
+				Exclude:     excluded(*randomExclude, *randomAmbiguous),
+			}.Policy()
+			if hasClassLimits(classes) {
+				policy.Classes = classPolicy(classes)
+			}
+			if rulesPolicy != nil {
+				policy = rulesPolicy.WithLength(length)
+			}
+			if *allowed != "" {
+				policy.Allowed = *allowed
+			}
+			policy.Forbidden = *forbidden + excluded(*randomExclude, *randomAmbiguous)
+			if *maxConsecutive != 0 {
+				policy.MaxConsecutive = *maxConsecutive
+			}
+			return policy
+		}
+		newRandom := func(length int) generator.Generator {
+			return generator.NewRandomWithPolicy(newPolicy(length))
+		}
+
+		length := *lenChars
+		if *randomBits > 0 {
+			length, err = generator.ForEntropy(*randomBits, newRandom)
+			if err != nil {
+				exitOnError(err.Error())
+			}
+		}
+		policy := newPolicy(length)
+		gen = generator.NewRandomWithPolicy(policy)
+		params = randomParams(policy)
 	case "pin":
-		_ = pin.Parse(args[1:])
-		pass, err = genPIN(r, *lenNums, *allowRepeat)
+		parseSubcommand(pin, args[1:])
+		if preset != nil {
+			if err := checkPreset(preset, pin, "length", "strong", "bits"); err != nil {
+				exitOnError(err.Error())
+			}
+			if *lenNums, err = presetBounds(preset, pin, "length", *lenNums, preset.PIN.Length, 0); err != nil {
+				exitOnError(err.Error())
+			}
+		}
+		opts := generator.PINOptions{
+			Length:      *lenNums,
+			AllowRepeat: *allowRepeat,
+			Exclude:     excluded(*pinExclude, *pinAmbiguous),
+		}
+		if preset != nil {
+			opts.Avoid = preset.PIN.Avoid
+		}
+		if *pinStrong {
+			opts.Avoid = generator.StrongPINRules
+		}
+		if *pinAvoid != "" {
+			opts.Avoid, err = generator.ParsePINRules(*pinAvoid)
+			if err != nil {
+				exitOnError(err.Error())
+			}
+		}
+		if opts.Avoid != (generator.PINRules{}) {
+			// Report the entropy left after filtering the weak patterns
+			*showEntropy = true
+		}
+		if *pinBits > 0 {
+			opts.Length, err = generator.ForEntropy(*pinBits, func(n int) generator.Generator {
+				o := opts
+				o.Length = n
+				return generator.NewPIN(o)
+			})
+			if err != nil {
+				exitOnError(err.Error())
+			}
+			if preset != nil && opts.Length < preset.PIN.Length {
+				opts.Length = preset.PIN.Length
+			}
+		}
+		gen = generator.NewPIN(opts)
+		params = pinParams(opts)
+	case "derive":
+		parseSubcommand(derive, args[1:])
+		if *site == "" {
+			exitOnError("-site is required")
+		}
+		master, err := readPassword("Master password: ")
+		if err != nil {
+			exitOnError(err.Error())
+		}
+		pass, err := generator.DeriveLessPass(master, generator.LessPassOptions{
+			Site:    *site,
+			Login:   *login,
+			Counter: *counter,
+			Length:  *lenDerived,
+			Lower:   *deriveLower,
+			Upper:   *deriveUpper,
+			Digits:  *deriveDigits,
+			Symbols: *deriveSymbols,
+		})
+		if err != nil {
+			exitOnError(err.Error())
+		}
+		fmt.Println(pass)
+		if !*noClipboard {
+			copyToClipboard(cb, pass, *clearAfter)
+		}
+		return
+	case "check":
+		parseSubcommand(check, args[1:])
+		candidate, err := readPassword("Password: ")
+		if err != nil {
+			exitOnError(err.Error())
+		}
+		printCheck(os.Stdout, strength.Check(candidate))
+		return
+	case "breached":
+		parseSubcommand(breached, args[1:])
+		if *hashFile == "" {
+			*hashFile = *checkBreached
+		}
+		if *hashFile == "" {
+			exitOnError("-file is required")
+		}
+		f, err := breach.Open(*hashFile)
+		if err != nil {
+			exitOnError(err.Error())
+		}
+		candidate, err := readPassword("Password: ")
+		if err != nil {
+			exitOnError(err.Error())
+		}
+		n, err := f.Lookup(candidate)
+		if err != nil {
+			exitOnError(err.Error())
+		}
+		if n > 0 {
+			exitOnError(fmt.Sprintf("Breached: seen %d times", n))
+		}
+		fmt.Println("Not found in known breaches")
+		return
+	case "serve":
+		parseSubcommand(serve, args[1:])
+		if *token == "" {
+			*token = os.Getenv("PWGENIE_TOKEN")
+		}
+		srv := &http.Server{
+			Addr:              *addr,
+			Handler:           newServer(*token, *rateLimit, *burst).handler(),
+			ReadHeaderTimeout: 10 * time.Second,
+			ReadTimeout:       30 * time.Second,
+			WriteTimeout:      30 * time.Second,
+		}
+		fmt.Fprintf(os.Stderr, "Listening on %s\n", *addr)
+		exitOnError(srv.ListenAndServe().Error())
+	case "daemon":
+		parseSubcommand(daemonCmd, args[1:])
+		if *socket == "" {
+			exitOnError("-socket is required")
+		}
+		mode, err := strconv.ParseUint(*socketMode, 8, 32)
+		if err != nil || mode > 0o777 {
+			exitOnError(fmt.Sprintf("invalid socket mode %q", *socketMode))
+		}
+		l, err := listenSocket(*socket, os.FileMode(mode), *socketGroup)
+		if err != nil {
+			exitOnError(err.Error())
+		}
+		// Close the listener on interrupt, which removes the socket
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-sig
+			l.Close()
+		}()
+		fmt.Fprintf(os.Stderr, "Listening on %s\n", *socket)
+		if err := (&daemon{profiles: daemonProfiles(cfg, *allowRepeat, os.Stderr)}).serve(l); err != nil {
+			exitOnError(err.Error())
+		}
+		return
+	case "presets":
+		parseSubcommand(presets, args[1:])
+		printPresets(os.Stdout)
+		return
 	default:
 		printHelp()
 	}
 
-	if err != nil {
-		exitOnError(err.Error())
+	if preset != nil {
+		params.Preset = preset.Name
 	}
 
-	// Print and copy to clipboard
-	if pass != "" {
-		fmt.Println(pass)
-		if !*noClipboard {
-			// Automatically write new pass to clipboard
-			_ = clipboard.WriteAll(pass)
+	if *checkBreached != "" {
+		f, err := breach.Open(*checkBreached)
+		if err != nil {
+			exitOnError(err.Error())
 		}
+		gen = generator.NewScreened(gen, func(pass string) (bool, error) {
+			n, err := f.Lookup(pass)
+			return n > 0, err
+		})
 	}
-}
-
-// genHuman generates a password with the given number of words, separated by the given
-// separator.
-// If capitalize is true, each word will be capitalized.
-func genHuman(r io.Reader, words int, separator string, capitalize, allowRepeat bool) (string, error) {
-	var (
-		formatted []string
-		result    string
-	)
 
-	if !allowRepeat && words > len(EFFWords) {
-		return result, ErrTooManyCharacters
+	if *count < 1 {
+		exitOnError("-count must be at least 1")
+	}
+	batch, err = generator.GenerateBatch(r, gen, generator.BatchOptions{
+		Count:   *count,
+		Unique:  *unique,
+		Workers: *workers,
+	})
+	if err != nil {
+		exitOnError(err.Error())
 	}
 
-	// Multiple choices from word list
-	for i := 0; i < words; i++ {
-		n, err := rand.Int(r, big.NewInt(int64(len(EFFWords))))
+	var bits float64
+	if *showEntropy || *format != "text" {
+		bits, err = gen.Entropy()
 		if err != nil {
-			return result, err
-		}
-		word := EFFWords[n.Int64()]
-
-		if !allowRepeat && slices.Contains(formatted, word) {
-			i--
-			continue
+			exitOnError(err.Error())
 		}
-
-		formatted = append(formatted, word)
 	}
 
-	// Join the formatted words with the separator
-	result = strings.Join(formatted, separator)
-
-	// Capitalize the result if requested
-	if capitalize {
-		result = cases.Title(language.English).String(result)
+	// Print and copy to clipboard
+	records := newRecords(batch, args[0], params, bits)
+	if alg != "" {
+		if err := hashRecords(records, alg, *hashOnly, *workers); err != nil {
+			exitOnError(err.Error())
+		}
 	}
-
-	return result, nil
-}
-
-// genRandom generates a password with the given number of characters
-// using the given character sets.
-// This follows Agiles 1Password: https://discussions.agilebits.com/discussion/23842/how-random-are-the-generated-passwords
-func genRandom(r io.Reader, length int, hasUpper, hasDigits, hasSymbols, allowRepeat bool) (string, error) {
-	var (
-		maxChars, numLowerChars, numUpperChars, numDigits, numSymbols int
-		result                                                        string
-	)
-
-	maxChars += len(LowerLetters)
-	if hasUpper {
-		maxChars += len(UpperLetters)
-		numUpperChars = 1
+	if err := writeRecords(os.Stdout, *format, records); err != nil {
+		exitOnError(err.Error())
 	}
-
-	if hasDigits {
-		maxChars += len(Digits)
-		numDigits = 1
+	if *showEntropy {
+		fmt.Fprintf(os.Stderr, "Entropy: %.2f bits\n", bits)
 	}
-
-	if hasSymbols {
-		maxChars += len(Symbols)
-		numSymbols = 1
+	// Copying a single password of a batch would be misleading, and the
+	// plaintext of -hash-only is only copied on request
+	if len(batch) == 1 && batch[0] != "" && !*noClipboard && (!*hashOnly || *hashCopy) {
+		// Automatically write new pass to clipboard
+		copyToClipboard(cb, batch[0], *clearAfter)
 	}
+}
 
-	if !allowRepeat && maxChars < length {
-		return result, ErrTooManyCharacters
+// parseSubcommand parses the options of a subcommand, which exits on
+// invalid options. Arguments left after the options are rejected rather
+// than ignored.
+func parseSubcommand(flags *flag.FlagSet, args []string) {
+	_ = flags.Parse(args)
+	if flags.NArg() > 0 {
+		exitOnError(fmt.Sprintf("unexpected argument %q after the options of %s", flags.Arg(0), flags.Name()))
 	}
+}
 
-	// calculate characters distributions
-	numUpperChars = calcNum(maxChars, len(UpperLetters), length, numUpperChars)
-	numDigits = calcNum(maxChars, len(Digits), length, numDigits)
-	numSymbols = calcNum(maxChars, len(Symbols), length, numSymbols)
-
-	// The rest is lowercase characters
-	numLowerChars = length - numUpperChars - numDigits - numSymbols
+// classFlags holds the command-line flags of a character class
+// of the random subcommand.
+type classFlags struct {
+	name     string
+	chars    string
+	enabled  *bool
+	min, max *int
+}
 
-	// Lower characters
-	for i := 0; i < numLowerChars; i++ {
-		ch, err := randElement(r, LowerLetters)
-		if err != nil {
-			return result, err
-		}
+// rulesConflicts are the options of random whose classes -rules replaces.
+var rulesConflicts = []string{
+	"upper", "digit", "symbol", "charset", "symbols",
+	"min-lower", "max-lower", "min-upper", "max-upper",
+	"min-digit", "max-digit", "min-symbol", "max-symbol",
+}
 
-		if !allowRepeat && strings.Contains(result, ch) {
-			i--
-			continue
+// checkRules returns an error if an option of flags replaced by -rules
+// was set.
+func checkRules(flags *flag.FlagSet) error {
+	var err error
+	flags.Visit(func(f *flag.Flag) {
+		if err == nil && containsString(rulesConflicts, f.Name) {
+			err = fmt.Errorf("-%s cannot be used with -rules, which sets the classes", f.Name)
 		}
+	})
+	return err
+}
 
-		result, err = randInsert(r, result, ch)
-		if err != nil {
-			return result, err
+// hasClassLimits reports whether any minimum or maximum class count was given.
+func hasClassLimits(classes []classFlags) bool {
+	for _, c := range classes {
+		if *c.min != 0 || *c.max != 0 {
+			return true
 		}
 	}
+	return false
+}
 
-	// Upper characters
-	for i := 0; i < numUpperChars; i++ {
-		ch, err := randElement(r, UpperLetters)
-		if err != nil {
-			return result, err
-		}
-
-		if !allowRepeat && strings.Contains(result, ch) {
-			i--
+// classPolicy converts the class flags to policy classes. A class enabled
+// with its switch gets at least one character; a class is also enabled by
+// giving it a limit.
+func classPolicy(classes []classFlags) []generator.CharClass {
+	var result []generator.CharClass
+	for _, c := range classes {
+		on := c.enabled == nil || *c.enabled
+		if !on && *c.min == 0 && *c.max == 0 {
 			continue
 		}
 
-		result, err = randInsert(r, result, ch)
-		if err != nil {
-			return result, err
+		cc := generator.CharClass{Name: c.name, Chars: c.chars, Min: *c.min, Max: *c.max}
+		if c.enabled != nil && *c.enabled && cc.Min == 0 {
+			cc.Min = 1
 		}
+		result = append(result, cc)
 	}
+	return result
+}
 
-	// Digits
-	for i := 0; i < numDigits; i++ {
-		ch, err := randElement(r, Digits)
-		if err != nil {
-			return result, err
-		}
-
-		if !allowRepeat && strings.Contains(result, ch) {
-			i--
-			continue
-		}
-
-		result, err = randInsert(r, result, ch)
-		if err != nil {
-			return result, err
-		}
+// excluded returns the characters excluded by the -exclude and
+// -exclude-ambiguous flags.
+func excluded(chars string, ambiguous bool) string {
+	if ambiguous {
+		chars += generator.Ambiguous
 	}
+	return chars
+}
 
-	// Symbols
-	for i := 0; i < numSymbols; i++ {
-		ch, err := randElement(r, Symbols)
+// humanWordlist returns the wordlist of the human subcommand. name is a
+// built-in wordlist or a file, which custom tells was given explicitly;
+// lang picks the built-in wordlist of a language unless custom is set, in
+// which case it only sets the language of the list.
+func humanWordlist(name string, custom bool, lang string, ascii bool) (*generator.Wordlist, error) {
+	list, ok := generator.LookupWordlist(name)
+	if !ok {
+		var err error
+		list, err = generator.LoadWordlist(name)
 		if err != nil {
-			return result, err
+			return nil, err
 		}
-
-		if !allowRepeat && strings.Contains(result, ch) {
-			i--
-			continue
-		}
-
-		result, err = randInsert(r, result, ch)
-		if err != nil {
-			return result, err
+		for _, w := range list.Warnings {
+			fmt.Fprintln(os.Stderr, "warning:", w)
 		}
 	}
 
-	return result, nil
-}
-
-// genPIN generates a PIN with the given number of numbers
-func genPIN(r io.Reader, length int, allowRepeat bool) (string, error) {
-	var result string
-
-	if !allowRepeat && length > len(Digits) {
-		return result, ErrTooManyCharacters
-	}
-
-	// Digits
-	for i := 0; i < length; i++ {
-		ch, err := randElement(r, Digits)
+	if lang != "" {
+		tag, err := language.Parse(lang)
 		if err != nil {
-			return result, err
+			return nil, fmt.Errorf("invalid language %q: %w", lang, err)
 		}
-
-		if !allowRepeat && strings.Contains(result, ch) {
-			i--
-			continue
+		if custom {
+			l := *list
+			l.Language = tag
+			list = &l
+		} else if list, ok = generator.WordlistForLanguage(tag); !ok {
+			return nil, fmt.Errorf("no built-in wordlist for language %s, use -wordlist with a file of words in this language", tag)
 		}
+	}
 
-		result, err = randInsert(r, result, ch)
+	if ascii {
+		var err error
+		list, err = list.ASCII()
 		if err != nil {
-			return result, err
+			return nil, err
+		}
+		for _, w := range list.Warnings {
+			fmt.Fprintln(os.Stderr, "warning:", w)
 		}
 	}
 
-	return result, nil
+	return list, nil
 }
 
-// randElement randonly gets an element from given string string
-func randElement(r io.Reader, s string) (string, error) {
-	n, err := rand.Int(r, big.NewInt(int64(len(s))))
-	if err != nil {
-		return "", err
+// algorithmNames returns the names of the hashing algorithms.
+func algorithmNames() string {
+	var names []string
+	for _, alg := range crypt.Algorithms() {
+		names = append(names, string(alg))
 	}
-	return string(s[n.Int64()]), nil
+	return strings.Join(names, ", ")
 }
 
-// randInsert randonly insert an element into given string
-func randInsert(r io.Reader, s, e string) (string, error) {
-	if s == "" {
-		return e, nil
-	}
-	n, err := rand.Int(r, big.NewInt(int64(len(s)+1)))
-	if err != nil {
-		return "", err
+// wordlistNames returns the names of the built-in wordlists.
+func wordlistNames() []string {
+	var names []string
+	for _, w := range generator.Wordlists() {
+		names = append(names, w.Name)
 	}
-	pos := n.Int64()
-	return s[0:pos] + e + s[pos:], nil
+	return names
 }
 
-// calcNum calculate the number of letters
-// based on character distribution in overall.
-func calcNum(total, avail, length, initVal int) int {
-	var result int
-
-	result = int(math.Floor(float64(avail) / float64(total) * float64(length)))
-	if result < 1 {
-		result = 1
+// languageNames returns the languages of the built-in wordlists.
+func languageNames() []string {
+	var names []string
+	for _, tag := range generator.Languages() {
+		names = append(names, tag.String())
 	}
-
-	return result * initVal
+	return names
 }
@@ -0,0 +1,254 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ntk148v/pwgenie/crypt"
+	"github.com/ntk148v/pwgenie/generator"
+)
+
+// formats are the output formats accepted by -format.
+var formats = []string{"text", "json", "csv", "ndjson"}
+
+// record is a generated password with the metadata printed by the
+// structured output formats. Password is empty with -hash-only.
+type record struct {
+	Password  string       `json:"password,omitempty"`
+	Hash      string       `json:"hash,omitempty"`
+	Mode      string       `json:"mode"`
+	Params    recordParams `json:"params"`
+	Entropy   float64      `json:"entropy"`
+	Timestamp time.Time    `json:"timestamp"`
+}
+
+// recordParams are the parameters a password was generated with. Only the
+// parameters of its mode are set.
+type recordParams struct {
+	Preset      string   `json:"preset,omitempty"`
+	Length      int      `json:"length,omitempty"`
+	MaxLength   int      `json:"maxLength,omitempty"`
+	Words       int      `json:"words,omitempty"`
+	Separator   *string  `json:"separator,omitempty"`
+	Capitalize  bool     `json:"capitalize,omitempty"`
+	Wordlist    string   `json:"wordlist,omitempty"`
+	Dice        bool     `json:"dice,omitempty"`
+	Classes     []string `json:"classes,omitempty"`
+	Avoid       []string `json:"avoid,omitempty"`
+	AllowRepeat bool     `json:"allowRepeat"`
+}
+
+// humanParams returns the parameters of the human mode.
+func humanParams(opts generator.HumanOptions, dice bool) recordParams {
+	name := generator.WordlistEFFLarge.Name
+	if opts.Wordlist != nil {
+		name = opts.Wordlist.Name
+	}
+	return recordParams{
+		Words:       opts.Words,
+		Separator:   &opts.Separator,
+		Capitalize:  opts.Capitalize,
+		Wordlist:    name,
+		Dice:        dice,
+		AllowRepeat: opts.AllowRepeat,
+	}
+}
+
+// randomParams returns the parameters of the random mode.
+func randomParams(p generator.Policy) recordParams {
+	params := recordParams{Length: p.MinLength, AllowRepeat: p.AllowRepeat}
+	if p.MaxLength > p.MinLength {
+		params.MaxLength = p.MaxLength
+	}
+	for _, c := range p.Classes {
+		params.Classes = append(params.Classes, c.Name)
+	}
+	return params
+}
+
+// pinParams returns the parameters of the pin mode.
+func pinParams(opts generator.PINOptions) recordParams {
+	return recordParams{Length: opts.Length, Avoid: opts.Avoid.Names(), AllowRepeat: opts.AllowRepeat}
+}
+
+// newRecords returns the records of a batch of passwords generated by the
+// given mode, all with the same parameters and entropy.
+func newRecords(batch []string, mode string, params recordParams, bits float64) []record {
+	records := make([]record, len(batch))
+	for i, pass := range batch {
+		records[i] = record{
+			Password:  pass,
+			Mode:      mode,
+			Params:    params,
+			Entropy:   bits,
+			Timestamp: time.Now().UTC(),
+		}
+	}
+	return records
+}
+
+// hashRecords sets the hash of the passwords of the records with the
+// given algorithm, removing the passwords if hashOnly is set. Hashing is
+// slow by design, so the passwords are hashed in parallel by the given
+// number of workers, runtime.GOMAXPROCS(0) if zero.
+func hashRecords(records []record, alg crypt.Algorithm, hashOnly bool, workers int) error {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(records) {
+		workers = len(records)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		next     = make(chan int)
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				h, err := crypt.Hash(alg, records[i].Password)
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+				records[i].Hash = h
+			}
+		}()
+	}
+	for i := range records {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	if hashOnly {
+		for i := range records {
+			records[i].Password = ""
+		}
+	}
+	return nil
+}
+
+// csvHeader is the first line of the csv format.
+var csvHeader = []string{"password", "hash", "mode", "preset", "length", "max_length", "words", "separator", "capitalize", "wordlist", "dice", "classes", "avoid", "allow_repeat", "entropy", "timestamp"}
+
+// validFormat reports whether format is one of formats.
+func validFormat(format string) bool {
+	for _, f := range formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// writeRecords writes the records to w in the given format. The text
+// format is the bare passwords, one per line.
+func writeRecords(w io.Writer, format string, records []record) error {
+	switch format {
+	case "text":
+		for _, rec := range records {
+			var fields []string
+			if rec.Password != "" || rec.Hash == "" {
+				fields = append(fields, rec.Password)
+			}
+			if rec.Hash != "" {
+				fields = append(fields, rec.Hash)
+			}
+			if _, err := fmt.Fprintln(w, strings.Join(fields, "\t")); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	case "ndjson":
+		enc := json.NewEncoder(w)
+		for _, rec := range records {
+			if err := enc.Encode(rec); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvHeader); err != nil {
+			return err
+		}
+		for _, rec := range records {
+			if err := cw.Write(rec.csv()); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		return fmt.Errorf("unknown format %q, want one of %s", format, strings.Join(formats, ", "))
+	}
+}
+
+// csv returns the fields of the record in the order of csvHeader.
+func (rec record) csv() []string {
+	p := rec.Params
+	var separator string
+	if p.Separator != nil {
+		separator = *p.Separator
+	}
+	return []string{
+		rec.Password,
+		rec.Hash,
+		rec.Mode,
+		p.Preset,
+		csvInt(p.Length),
+		csvInt(p.MaxLength),
+		csvInt(p.Words),
+		separator,
+		strconv.FormatBool(p.Capitalize),
+		p.Wordlist,
+		strconv.FormatBool(p.Dice),
+		strings.Join(p.Classes, " "),
+		strings.Join(p.Avoid, " "),
+		strconv.FormatBool(p.AllowRepeat),
+		strconv.FormatFloat(rec.Entropy, 'f', 2, 64),
+		rec.Timestamp.Format(time.RFC3339),
+	}
+}
+
+// csvInt formats n, leaving the field empty for zero.
+func csvInt(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
@@ -0,0 +1,171 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ntk148v/pwgenie/crypt"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testRecords returns records with passwords that need quoting in csv.
+func testRecords() []record {
+	sep := ","
+	ts := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	return []record{
+		{
+			Password:  `a,b"c`,
+			Mode:      "random",
+			Params:    recordParams{Length: 5, Classes: []string{"lower", "symbol"}},
+			Entropy:   30.5,
+			Timestamp: ts,
+		},
+		{
+			Password:  "one,two",
+			Mode:      "human",
+			Params:    recordParams{Words: 2, Separator: &sep, Wordlist: "eff-large"},
+			Entropy:   25.85,
+			Timestamp: ts,
+		},
+	}
+}
+
+func TestWriteRecords(t *testing.T) {
+	t.Parallel()
+
+	hashed := []record{{Hash: "$2a$10$hash", Mode: "pin", Params: recordParams{Length: 6}}}
+	both := []record{{Password: "secret", Hash: "$2a$10$hash", Mode: "pin"}}
+	tests := []struct {
+		name, format string
+		records      []record
+		want         string
+	}{
+		{"text", "text", testRecords(), "a,b\"c\none,two\n"},
+		{"text_hash_only", "text", hashed, "$2a$10$hash\n"},
+		{"text_hash", "text", both, "secret\t$2a$10$hash\n"},
+		{"ndjson", "ndjson", testRecords(), `{"password":"a,b\"c","mode":"random","params":{"length":5,"classes":["lower","symbol"],"allowRepeat":false},"entropy":30.5,"timestamp":"2023-05-01T12:00:00Z"}
+{"password":"one,two","mode":"human","params":{"words":2,"separator":",","wordlist":"eff-large","allowRepeat":false},"entropy":25.85,"timestamp":"2023-05-01T12:00:00Z"}
+`},
+		{"ndjson_hash_only", "ndjson", hashed, `{"hash":"$2a$10$hash","mode":"pin","params":{"length":6,"allowRepeat":false},"entropy":0,"timestamp":"0001-01-01T00:00:00Z"}
+`},
+		{"csv", "csv", testRecords(), `password,hash,mode,preset,length,max_length,words,separator,capitalize,wordlist,dice,classes,avoid,allow_repeat,entropy,timestamp
+"a,b""c",,random,,5,,,,false,,false,lower symbol,,false,30.50,2023-05-01T12:00:00Z
+"one,two",,human,,,,2,",",false,eff-large,false,,,false,25.85,2023-05-01T12:00:00Z
+`},
+		{"csv_hash_only", "csv", hashed, `password,hash,mode,preset,length,max_length,words,separator,capitalize,wordlist,dice,classes,avoid,allow_repeat,entropy,timestamp
+,$2a$10$hash,pin,,6,,,,false,,false,,,false,0.00,0001-01-01T00:00:00Z
+`},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			if err := writeRecords(&buf, tt.format, tt.records); err != nil {
+				t.Fatalf("writeRecords: %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteRecordsRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	want := testRecords()
+
+	var buf bytes.Buffer
+	if err := writeRecords(&buf, "json", want); err != nil {
+		t.Fatalf("writeRecords json: %v", err)
+	}
+	var got []record
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("json output does not parse: %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d json records, want %d", len(got), len(want))
+	}
+	for i := range got {
+		if got[i].Password != want[i].Password || got[i].Mode != want[i].Mode || !got[i].Timestamp.Equal(want[i].Timestamp) {
+			t.Errorf("json record %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	buf.Reset()
+	if err := writeRecords(&buf, "csv", want); err != nil {
+		t.Fatalf("writeRecords csv: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("csv output does not parse: %v", err)
+	}
+	if len(rows) != len(want)+1 {
+		t.Fatalf("got %d csv rows, want %d", len(rows), len(want)+1)
+	}
+	for i, rec := range want {
+		if rows[i+1][0] != rec.Password {
+			t.Errorf("csv row %d: got password %q, want %q", i+1, rows[i+1][0], rec.Password)
+		}
+	}
+}
+
+func TestWriteRecordsUnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	err := writeRecords(&bytes.Buffer{}, "yaml", testRecords())
+	if err == nil || !strings.Contains(err.Error(), "unknown format") {
+		t.Errorf("got %v, want an unknown format error", err)
+	}
+}
+
+func TestHashRecords(t *testing.T) {
+	t.Parallel()
+
+	for _, hashOnly := range []bool{false, true} {
+		records := newRecords([]string{"first", "second", "third"}, "random", recordParams{}, 10)
+		if err := hashRecords(records, crypt.Bcrypt, hashOnly, 2); err != nil {
+			t.Fatalf("hashRecords hashOnly=%t: %v", hashOnly, err)
+		}
+		for i, pass := range []string{"first", "second", "third"} {
+			rec := records[i]
+			if err := bcrypt.CompareHashAndPassword([]byte(rec.Hash), []byte(pass)); err != nil {
+				t.Errorf("hashOnly=%t: record %d: hash %q does not match %q: %v", hashOnly, i, rec.Hash, pass, err)
+			}
+			if hashOnly && rec.Password != "" {
+				t.Errorf("hashOnly=%t: record %d kept its password %q", hashOnly, i, rec.Password)
+			}
+			if !hashOnly && rec.Password != pass {
+				t.Errorf("hashOnly=%t: record %d: got password %q, want %q", hashOnly, i, rec.Password, pass)
+			}
+		}
+	}
+
+	records := newRecords([]string{"short", strings.Repeat("x", 73)}, "random", recordParams{}, 10)
+	if err := hashRecords(records, crypt.Bcrypt, true, 0); err == nil {
+		t.Error("hashRecords: got no error for a password bcrypt cannot hash")
+	}
+	if records[0].Password == "" {
+		t.Error("hashRecords removed the passwords after an error")
+	}
+}
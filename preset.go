@@ -0,0 +1,104 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ntk148v/pwgenie/generator"
+)
+
+// lookupPreset returns the built-in preset with the given name, nil if
+// name is empty.
+func lookupPreset(name string) (*generator.Preset, error) {
+	if name == "" {
+		return nil, nil
+	}
+	p, ok := generator.LookupPreset(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown preset %q, want one of %s", name, strings.Join(presetNames(), ", "))
+	}
+	return &p, nil
+}
+
+// presetNames returns the names of the built-in presets.
+func presetNames() []string {
+	var names []string
+	for _, p := range generator.Presets() {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// checkPreset returns an error if the preset does not apply to the
+// subcommand of flags, or if a flag other than allowed was set: the other
+// flags could break the policy of the preset.
+func checkPreset(preset *generator.Preset, flags *flag.FlagSet, allowed ...string) error {
+	modes := preset.Modes()
+	if !containsString(modes, flags.Name()) {
+		return fmt.Errorf("preset %s does not apply to %s, only to %s", preset.Name, flags.Name(), strings.Join(modes, ", "))
+	}
+
+	var err error
+	flags.Visit(func(f *flag.Flag) {
+		if err == nil && !containsString(allowed, f.Name) {
+			err = fmt.Errorf("-%s cannot be used with -preset %s", f.Name, preset.Name)
+		}
+	})
+	return err
+}
+
+// presetBounds returns the value of the flag name of flags, or min if
+// the flag was not set. A value below min, or above max when max is not
+// zero, is an error.
+func presetBounds(preset *generator.Preset, flags *flag.FlagSet, name string, value, min, max int) (int, error) {
+	set := false
+	flags.Visit(func(f *flag.Flag) { set = set || f.Name == name })
+	if !set {
+		return min, nil
+	}
+	if value < min {
+		return 0, fmt.Errorf("-%s must be at least %d with -preset %s", name, min, preset.Name)
+	}
+	if max != 0 && value > max {
+		return 0, fmt.Errorf("-%s must be at most %d with -preset %s", name, max, preset.Name)
+	}
+	return value, nil
+}
+
+// printPresets prints the built-in presets, the modes they apply to and
+// their rationale.
+func printPresets(w io.Writer) {
+	for i, p := range generator.Presets() {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s\t%s (%s)\n", p.Name, p.Standard, strings.Join(p.Modes(), ", "))
+		fmt.Fprintf(w, "\t%s\n", p.Rationale)
+	}
+}
+
+// containsString reports whether s is in list.
+func containsString(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
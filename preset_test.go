@@ -0,0 +1,55 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"io"
+	"testing"
+)
+
+func TestPresetBounds(t *testing.T) {
+	t.Parallel()
+
+	preset, err := lookupPreset("aws-iam")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		args []string
+		want int
+		err  bool
+	}{
+		{nil, 8, false},
+		{[]string{"-length=8"}, 8, false},
+		{[]string{"-length=64"}, 64, false},
+		{[]string{"-length=128"}, 128, false},
+		{[]string{"-length=7"}, 0, true},
+		{[]string{"-length=129"}, 0, true},
+	}
+	for _, tt := range tests {
+		flags := flag.NewFlagSet("random", flag.ContinueOnError)
+		flags.SetOutput(io.Discard)
+		length := flags.Int("length", 12, "")
+		if err := flags.Parse(tt.args); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := presetBounds(preset, flags, "length", *length, preset.Random.MinLength, preset.Random.MaxLength)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("%q: got %d, %v, want %d", tt.args, got, err, tt.want)
+		}
+	}
+}
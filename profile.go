@@ -0,0 +1,168 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/ntk148v/pwgenie/generator"
+)
+
+// profile is a named set of generation options.
+type profile struct {
+	// Mode is the generation mode: human, random or pin.
+	Mode string `json:"mode"`
+	// Options are the options of the mode, as in the body of the HTTP
+	// endpoint of the mode.
+	Options json.RawMessage `json:"options,omitempty"`
+}
+
+// builtinProfiles are the profiles available without configuration.
+var builtinProfiles = map[string]profile{
+	"human":      {Mode: "human"},
+	"random":     {Mode: "random"},
+	"pin":        {Mode: "pin"},
+	"passphrase": {Mode: "human", Options: json.RawMessage(`{"words": 6, "separator": "-"}`)},
+	"password":   {Mode: "random", Options: json.RawMessage(`{"length": 20, "upper": true, "digit": true, "symbol": true}`)},
+	"api-key":    {Mode: "random", Options: json.RawMessage(`{"length": 32, "upper": true, "digit": true}`)},
+}
+
+// generator returns the generator of the profile, its parameters and the
+// batch options of the profile.
+func (p profile) generator() (generator.Generator, recordParams, batchRequest, error) {
+	parse, ok := requestParsers[p.Mode]
+	if !ok {
+		return nil, recordParams{}, batchRequest{}, fmt.Errorf("unknown mode %q", p.Mode)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(p.Options))
+	dec.DisallowUnknownFields()
+	return parse(dec)
+}
+
+// profileOptions maps the options of the subcommands, as given by the
+// configuration profiles, to the options of the profiles of their mode.
+// The other options, such as -bits or -min-digit, have no equivalent.
+var profileOptions = map[string]map[string]string{
+	"human": {
+		"words": "words", "sep": "separator", "cap": "capitalize",
+		"wordlist": "wordlist", "lang": "lang", "ascii": "ascii",
+	},
+	"random": {
+		"length": "length", "upper": "upper", "digit": "digit", "symbol": "symbol",
+		"charset": "charset", "symbols": "symbols", "rules": "rules",
+		"exclude": "exclude", "exclude-ambiguous": "excludeAmbiguous",
+	},
+	"pin": {
+		"length": "length", "exclude": "exclude", "exclude-ambiguous": "excludeAmbiguous",
+	},
+}
+
+// profile returns the profile equivalent to the configuration profile, if
+// its subcommand and options have one.
+func (p configProfile) profile() (profile, error) {
+	fields, ok := profileOptions[p.Command]
+	if !ok {
+		return profile{}, fmt.Errorf("subcommand %q has no profile equivalent", p.Command)
+	}
+
+	options := make(map[string]interface{}, len(p.Options))
+	for name, v := range p.Options {
+		field, ok := fields[name]
+		if !ok {
+			return profile{}, fmt.Errorf("option -%s of %s has no profile equivalent", name, p.Command)
+		}
+		options[field] = v
+	}
+	raw, err := json.Marshal(options)
+	if err != nil {
+		return profile{}, err
+	}
+
+	prof := profile{Mode: p.Command, Options: raw}
+	if _, _, _, err := prof.generator(); err != nil {
+		return profile{}, err
+	}
+	return prof, nil
+}
+
+// withAllowRepeat returns the profile allowing repeat characters, unless
+// its options already say whether they are allowed.
+func (p profile) withAllowRepeat() (profile, error) {
+	options := make(map[string]json.RawMessage)
+	if len(p.Options) > 0 {
+		if err := json.Unmarshal(p.Options, &options); err != nil {
+			return profile{}, err
+		}
+	}
+	if _, ok := options["allowRepeat"]; ok {
+		return p, nil
+	}
+	options["allowRepeat"] = json.RawMessage("true")
+	raw, err := json.Marshal(options)
+	if err != nil {
+		return profile{}, err
+	}
+	p.Options = raw
+	return p, nil
+}
+
+// daemonProfiles returns the profiles served by the daemon: the built-in
+// profiles and the profiles of the configuration, which replace the
+// built-in profiles of the same name. allowRepeat is the value of the
+// global -allow-repeat flag, defaults applied, which the profiles follow
+// like the subcommands do. The configuration profiles without an
+// equivalent are skipped with a warning written to warn.
+func daemonProfiles(cfg *config, allowRepeat bool, warn io.Writer) map[string]profile {
+	profiles := make(map[string]profile, len(builtinProfiles)+len(cfg.Profiles))
+	for name, p := range builtinProfiles {
+		profiles[name] = p
+	}
+	for name, p := range cfg.Profiles {
+		prof, err := p.profile()
+		if err != nil {
+			fmt.Fprintf(warn, "warning: profile %s is not served: %v\n", name, err)
+			continue
+		}
+		profiles[name] = prof
+	}
+	if !allowRepeat {
+		return profiles
+	}
+	for name, p := range profiles {
+		prof, err := p.withAllowRepeat()
+		if err != nil {
+			fmt.Fprintf(warn, "warning: profile %s is not served: %v\n", name, err)
+			delete(profiles, name)
+			continue
+		}
+		profiles[name] = prof
+	}
+	return profiles
+}
+
+// profileNames returns the sorted names of the profiles.
+func profileNames(profiles map[string]profile) []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,427 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"container/list"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/text/language"
+
+	"github.com/ntk148v/pwgenie/generator"
+)
+
+// Limits of the requests accepted by the server.
+const (
+	maxRequestBytes = 64 << 10
+	maxServeCount   = 1000
+	maxServeLength  = 1024
+	maxServeWords   = 100
+	// maxServeBatch bounds the work of a request: the count times the
+	// length, or the number of words, of the passwords.
+	maxServeBatch = 64 << 10
+)
+
+// errBadRequest is the error returned for invalid generation requests.
+var errBadRequest = errors.New("invalid request")
+
+// humanRequest holds the options of a human request, given by the body of
+// the human endpoint or by a profile.
+type humanRequest struct {
+	Words       int    `json:"words"`
+	Separator   string `json:"separator"`
+	Capitalize  bool   `json:"capitalize"`
+	AllowRepeat bool   `json:"allowRepeat"`
+	Wordlist    string `json:"wordlist"`
+	Lang        string `json:"lang"`
+	ASCII       bool   `json:"ascii"`
+	batchRequest
+}
+
+// randomRequest holds the options of a random request, given by the body of
+// the random endpoint or by a profile.
+type randomRequest struct {
+	Length           int    `json:"length"`
+	Upper            bool   `json:"upper"`
+	Digit            bool   `json:"digit"`
+	Symbol           bool   `json:"symbol"`
+	AllowRepeat      bool   `json:"allowRepeat"`
+	Charset          string `json:"charset"`
+	Symbols          string `json:"symbols"`
+	Exclude          string `json:"exclude"`
+	ExcludeAmbiguous bool   `json:"excludeAmbiguous"`
+	Rules            string `json:"rules"`
+	batchRequest
+}
+
+// pinRequest holds the options of a pin request, given by the body of
+// the pin endpoint or by a profile.
+type pinRequest struct {
+	Length           int    `json:"length"`
+	AllowRepeat      bool   `json:"allowRepeat"`
+	Exclude          string `json:"exclude"`
+	ExcludeAmbiguous bool   `json:"excludeAmbiguous"`
+	batchRequest
+}
+
+// batchRequest holds the batch options shared by every endpoint.
+type batchRequest struct {
+	Count  int  `json:"count"`
+	Unique bool `json:"unique"`
+}
+
+// server is the HTTP API of the serve subcommand.
+type server struct {
+	token   string
+	limiter *rateLimiter
+}
+
+// newServer returns a server requiring the given bearer token, if not
+// empty, and allowing each client rate requests per second with bursts of
+// burst requests. A zero rate disables rate limiting.
+func newServer(token string, rate float64, burst int) *server {
+	s := &server{token: token}
+	if rate > 0 {
+		s.limiter = newRateLimiter(rate, burst)
+	}
+	return s
+}
+
+// handler returns the HTTP handler of the server.
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", s.health)
+	for mode := range requestParsers {
+		mux.Handle("/v1/"+mode, s.protect(s.generate(mode)))
+	}
+	return mux
+}
+
+// health reports that the server is up. It is neither authenticated nor
+// rate limited, for load balancers and supervisors.
+func (s *server) health(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// protect wraps h with the rate limiter and the bearer token check. The
+// rate limit comes first, so that guessing the token is throttled too.
+func (s *server) protect(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.limiter != nil && !s.limiter.allow(clientAddr(r)) {
+			w.Header().Set("Retry-After", "1")
+			writeError(w, http.StatusTooManyRequests, errors.New("rate limit exceeded"))
+			return
+		}
+		if s.token != "" {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="pwgenie"`)
+				writeError(w, http.StatusUnauthorized, errors.New("missing or invalid bearer token"))
+				return
+			}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// generate returns the handler of the generation endpoint of a mode.
+func (s *server) generate(mode string) http.Handler {
+	parse := requestParsers[mode]
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+			return
+		}
+
+		dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+		dec.DisallowUnknownFields()
+		gen, params, batch, err := parse(dec)
+		if err == nil {
+			err = batch.validate(params)
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		bits, err := gen.Entropy()
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		passwords, err := generator.GenerateBatch(rand.Reader, gen, generator.BatchOptions{
+			Count:   batch.Count,
+			Unique:  batch.Unique,
+			Workers: 1,
+		})
+		if err != nil {
+			writeError(w, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, newRecords(passwords, mode, params, bits))
+	})
+}
+
+// requestParser decodes a generation request into the generator, its
+// parameters and the batch options.
+type requestParser func(*json.Decoder) (generator.Generator, recordParams, batchRequest, error)
+
+// requestParsers are the request parsers of each mode.
+var requestParsers = map[string]requestParser{
+	"human":  parseHuman,
+	"random": parseRandom,
+	"pin":    parsePIN,
+}
+
+// parseHuman parses a human request. Only the built-in wordlists are
+// available.
+func parseHuman(dec *json.Decoder) (generator.Generator, recordParams, batchRequest, error) {
+	req := humanRequest{Words: 5, Separator: " ", Wordlist: "eff-large"}
+	if err := decodeRequest(dec, &req); err != nil {
+		return nil, recordParams{}, req.batchRequest, err
+	}
+	if req.Words < 1 || req.Words > maxServeWords {
+		return nil, recordParams{}, req.batchRequest, fmt.Errorf("%w: words must be between 1 and %d", errBadRequest, maxServeWords)
+	}
+
+	list, ok := generator.LookupWordlist(req.Wordlist)
+	if !ok {
+		return nil, recordParams{}, req.batchRequest, fmt.Errorf("%w: unknown wordlist %q", errBadRequest, req.Wordlist)
+	}
+	if req.Lang != "" {
+		tag, err := language.Parse(req.Lang)
+		if err != nil {
+			return nil, recordParams{}, req.batchRequest, fmt.Errorf("%w: invalid language %q", errBadRequest, req.Lang)
+		}
+		if list, ok = generator.WordlistForLanguage(tag); !ok {
+			return nil, recordParams{}, req.batchRequest, fmt.Errorf("%w: no built-in wordlist for language %s", errBadRequest, tag)
+		}
+	}
+	if req.ASCII {
+		var err error
+		if list, err = list.ASCII(); err != nil {
+			return nil, recordParams{}, req.batchRequest, err
+		}
+	}
+
+	opts := generator.HumanOptions{
+		Words:       req.Words,
+		Separator:   req.Separator,
+		Capitalize:  req.Capitalize,
+		AllowRepeat: req.AllowRepeat,
+		Wordlist:    list,
+	}
+	return generator.NewHuman(opts), humanParams(opts, false), req.batchRequest, nil
+}
+
+// parseRandom parses a random request.
+func parseRandom(dec *json.Decoder) (generator.Generator, recordParams, batchRequest, error) {
+	req := randomRequest{Length: 8}
+	if err := decodeRequest(dec, &req); err != nil {
+		return nil, recordParams{}, req.batchRequest, err
+	}
+	if req.Length < 1 || req.Length > maxServeLength {
+		return nil, recordParams{}, req.batchRequest, fmt.Errorf("%w: length must be between 1 and %d", errBadRequest, maxServeLength)
+	}
+
+	exclude := excluded(req.Exclude, req.ExcludeAmbiguous)
+	policy := generator.RandomOptions{
+		Length:      req.Length,
+		Upper:       req.Upper,
+		Digits:      req.Digit,
+		Symbols:     req.Symbol || req.Symbols != "",
+		AllowRepeat: req.AllowRepeat,
+		Charset:     req.Charset,
+		SymbolSet:   req.Symbols,
+		Exclude:     exclude,
+	}.Policy()
+	if req.Rules != "" {
+		if req.Upper || req.Digit || req.Symbol || req.Charset != "" || req.Symbols != "" {
+			return nil, recordParams{}, req.batchRequest, fmt.Errorf("%w: rules cannot be combined with upper, digit, symbol, charset or symbols", errBadRequest)
+		}
+		p, err := generator.ParsePasswordRules(req.Rules)
+		if err != nil {
+			return nil, recordParams{}, req.batchRequest, fmt.Errorf("%w: %v", errBadRequest, err)
+		}
+		p.AllowRepeat = req.AllowRepeat
+		p.Forbidden = exclude
+		policy = p.WithLength(req.Length)
+		if policy.MinLength > maxServeLength {
+			return nil, recordParams{}, req.batchRequest, fmt.Errorf("%w: length must be between 1 and %d", errBadRequest, maxServeLength)
+		}
+	}
+
+	return generator.NewRandomWithPolicy(policy), randomParams(policy), req.batchRequest, nil
+}
+
+// parsePIN parses a pin request.
+func parsePIN(dec *json.Decoder) (generator.Generator, recordParams, batchRequest, error) {
+	req := pinRequest{Length: 6}
+	if err := decodeRequest(dec, &req); err != nil {
+		return nil, recordParams{}, req.batchRequest, err
+	}
+	if req.Length < 1 || req.Length > maxServeLength {
+		return nil, recordParams{}, req.batchRequest, fmt.Errorf("%w: length must be between 1 and %d", errBadRequest, maxServeLength)
+	}
+
+	opts := generator.PINOptions{
+		Length:      req.Length,
+		AllowRepeat: req.AllowRepeat,
+		Exclude:     excluded(req.Exclude, req.ExcludeAmbiguous),
+	}
+	return generator.NewPIN(opts), pinParams(opts), req.batchRequest, nil
+}
+
+// validate checks the batch options of passwords with the given
+// parameters, defaulting the count to one password.
+func (b *batchRequest) validate(params recordParams) error {
+	if b.Count == 0 {
+		b.Count = 1
+	}
+	if b.Count < 1 || b.Count > maxServeCount {
+		return fmt.Errorf("%w: count must be between 1 and %d", errBadRequest, maxServeCount)
+	}
+
+	size := params.Words
+	if params.Length > size {
+		size = params.Length
+	}
+	if params.MaxLength > size {
+		size = params.MaxLength
+	}
+	if size*b.Count > maxServeBatch {
+		return fmt.Errorf("%w: count times length must be at most %d", errBadRequest, maxServeBatch)
+	}
+	return nil
+}
+
+// decodeRequest decodes a JSON request body into v. An empty body keeps
+// the defaults of v.
+func decodeRequest(dec *json.Decoder, v interface{}) error {
+	if err := dec.Decode(v); err != nil && err != io.EOF {
+		return fmt.Errorf("%w: %v", errBadRequest, err)
+	}
+	if dec.More() {
+		return fmt.Errorf("%w: trailing data after the JSON object", errBadRequest)
+	}
+	return nil
+}
+
+// writeJSON writes v as the JSON response body with the given status.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes err as a JSON error response with the given status.
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// clientAddr returns the address rate limits are applied to: the IP
+// address of the client, without its port.
+func clientAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// rateLimiter is a token bucket rate limiter per client.
+type rateLimiter struct {
+	mu    sync.Mutex
+	rate  float64
+	burst float64
+	// buckets holds the elements of lru by client.
+	buckets map[string]*list.Element
+	// lru is the list of the buckets, the most recently seen client first.
+	lru *list.List
+	now func() time.Time
+}
+
+// bucket holds the tokens left to a client.
+type bucket struct {
+	client string
+	tokens float64
+	last   time.Time
+}
+
+// maxBuckets is the number of clients after which the rate limiter forgets
+// the least recently seen client.
+const maxBuckets = 10000
+
+// newRateLimiter returns a rate limiter allowing rate requests per second
+// with bursts of burst requests.
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*list.Element),
+		lru:     list.New(),
+		now:     time.Now,
+	}
+}
+
+// allow reports whether client may make a request now, and takes a token
+// from its bucket if so.
+func (l *rateLimiter) allow(client string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	e, ok := l.buckets[client]
+	if ok {
+		l.lru.MoveToFront(e)
+	} else {
+		for l.lru.Len() >= maxBuckets {
+			delete(l.buckets, l.lru.Remove(l.lru.Back()).(*bucket).client)
+		}
+		e = l.lru.PushFront(&bucket{client: client, tokens: l.burst, last: now})
+		l.buckets[client] = e
+	}
+
+	b := e.Value.(*bucket)
+	b.tokens = l.refill(b, now)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// refill returns the tokens of b at the given time.
+func (l *rateLimiter) refill(b *bucket, now time.Time) float64 {
+	return math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+}
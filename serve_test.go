@@ -0,0 +1,220 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// serveRequest sends a request to the handler of s and returns the
+// response.
+func serveRequest(s *server, method, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	s.handler().ServeHTTP(w, req)
+	return w
+}
+
+func TestServeGenerate(t *testing.T) {
+	t.Parallel()
+
+	s := newServer("", 0, 0)
+	tests := []struct {
+		path, body string
+		count      int
+		check      func(pass string) bool
+	}{
+		{"/v1/human", "", 1, func(p string) bool { return len(strings.Fields(p)) == 5 }},
+		{"/v1/human", `{"words": 3, "separator": "-", "count": 2}`, 2, func(p string) bool { return strings.Count(p, "-") == 2 }},
+		{"/v1/random", `{"length": 16, "upper": true, "digit": true}`, 1, func(p string) bool { return utf8.RuneCountInString(p) == 16 }},
+		{"/v1/random", `{"length": 20, "rules": "required: digit; allowed: lower;", "count": 3}`, 3, func(p string) bool { return len(p) == 20 }},
+		{"/v1/pin", `{"length": 8, "count": 5, "unique": true}`, 5, func(p string) bool { return len(p) == 8 }},
+	}
+	for _, tt := range tests {
+		w := serveRequest(s, http.MethodPost, tt.path, "", tt.body)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s %s: got status %d: %s", tt.path, tt.body, w.Code, w.Body)
+		}
+		var records []record
+		if err := json.Unmarshal(w.Body.Bytes(), &records); err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != tt.count {
+			t.Errorf("%s %s: got %d records, want %d", tt.path, tt.body, len(records), tt.count)
+		}
+		for _, rec := range records {
+			if !tt.check(rec.Password) || rec.Mode != strings.TrimPrefix(tt.path, "/v1/") || rec.Entropy <= 0 {
+				t.Errorf("%s %s: unexpected record %+v", tt.path, tt.body, rec)
+			}
+		}
+	}
+}
+
+func TestServeValidation(t *testing.T) {
+	t.Parallel()
+
+	s := newServer("", 0, 0)
+	tests := []struct {
+		method, path, body string
+		status             int
+	}{
+		{http.MethodGet, "/v1/random", "", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/v1/random", `{"length": 0}`, http.StatusBadRequest},
+		{http.MethodPost, "/v1/random", `{"length": 2000}`, http.StatusBadRequest},
+		{http.MethodPost, "/v1/random", `{"lenght": 8}`, http.StatusBadRequest},
+		{http.MethodPost, "/v1/random", `{"length": 8} {}`, http.StatusBadRequest},
+		{http.MethodPost, "/v1/random", `{"rules": "minlength: x;"}`, http.StatusBadRequest},
+		{http.MethodPost, "/v1/random", `{"length": 1024, "allowRepeat": true, "count": 100}`, http.StatusBadRequest},
+		{http.MethodPost, "/v1/random", `{"length": 30}`, http.StatusBadRequest},
+		{http.MethodPost, "/v1/random", `{"rules": "required: digit;", "upper": true}`, http.StatusBadRequest},
+		{http.MethodPost, "/v1/random", `{"rules": "minlength: 10; maxlength: 8;"}`, http.StatusBadRequest},
+		{http.MethodPost, "/v1/human", `{"words": 101}`, http.StatusBadRequest},
+		{http.MethodPost, "/v1/human", `{"wordlist": "/etc/passwd"}`, http.StatusBadRequest},
+		{http.MethodPost, "/v1/human", `{"lang": "xx-invalid-"}`, http.StatusBadRequest},
+		{http.MethodPost, "/v1/pin", `{"count": 1001}`, http.StatusBadRequest},
+		{http.MethodPost, "/v1/pin", `{"count": -1}`, http.StatusBadRequest},
+		{http.MethodPost, "/v1/pin", `{"length": 2, "count": 1000, "unique": true}`, http.StatusUnprocessableEntity},
+		{http.MethodPost, "/v1/unknown", `{}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		w := serveRequest(s, tt.method, tt.path, "", tt.body)
+		if w.Code != tt.status {
+			t.Errorf("%s %s %s: got status %d, want %d: %s", tt.method, tt.path, tt.body, w.Code, tt.status, w.Body)
+		}
+	}
+}
+
+func TestServeToken(t *testing.T) {
+	t.Parallel()
+
+	s := newServer("secret", 0, 0)
+	for token, status := range map[string]int{
+		"":        http.StatusUnauthorized,
+		"wrong":   http.StatusUnauthorized,
+		"secret!": http.StatusUnauthorized,
+		"secret":  http.StatusOK,
+	} {
+		w := serveRequest(s, http.MethodPost, "/v1/pin", token, "")
+		if w.Code != status {
+			t.Errorf("token %q: got status %d, want %d", token, w.Code, status)
+		}
+		if status == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("token %q: missing WWW-Authenticate header", token)
+		}
+	}
+
+	if w := serveRequest(s, http.MethodGet, "/healthz", "", ""); w.Code != http.StatusOK {
+		t.Errorf("health check: got status %d", w.Code)
+	}
+}
+
+func TestServeRateLimit(t *testing.T) {
+	t.Parallel()
+
+	// Failed authentications are rate limited too.
+	s := newServer("secret", 1, 2)
+	want := []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests}
+	for i, status := range want {
+		if w := serveRequest(s, http.MethodPost, "/v1/pin", "wrong", ""); w.Code != status {
+			t.Errorf("request %d: got status %d, want %d", i, w.Code, status)
+		}
+	}
+	if w := serveRequest(s, http.MethodPost, "/v1/pin", "secret", ""); w.Code != http.StatusTooManyRequests {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+	l := newRateLimiter(2, 3)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if !l.allow("a") {
+			t.Fatalf("request %d of the burst should be allowed", i)
+		}
+	}
+	if l.allow("a") {
+		t.Error("request after the burst should be denied")
+	}
+	if !l.allow("b") {
+		t.Error("another client should be allowed")
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if !l.allow("a") {
+		t.Error("a token should be refilled after 1/rate seconds")
+	}
+	if l.allow("a") {
+		t.Error("a single token should be refilled")
+	}
+
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		if !l.allow("a") {
+			t.Fatalf("request %d: the bucket should be full again", i)
+		}
+	}
+	if l.allow("a") {
+		t.Error("the bucket should not exceed the burst")
+	}
+}
+
+func TestRateLimiterEviction(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+	l := newRateLimiter(1, 1)
+	l.now = func() time.Time { return now }
+
+	if !l.allow("first") || !l.allow("recent") {
+		t.Fatal("the first request of a client should be allowed")
+	}
+	for i := 2; i < maxBuckets; i++ {
+		l.allow(fmt.Sprint(i))
+	}
+	// Seeing "recent" again makes "first" the least recently seen client.
+	if l.allow("recent") {
+		t.Error("the bucket of recent should be empty")
+	}
+	if len(l.buckets) != maxBuckets {
+		t.Fatalf("got %d buckets, want %d", len(l.buckets), maxBuckets)
+	}
+
+	if !l.allow("new") {
+		t.Error("a new client should be allowed")
+	}
+	if len(l.buckets) != maxBuckets || l.lru.Len() != maxBuckets {
+		t.Errorf("got %d buckets and %d list elements, want %d", len(l.buckets), l.lru.Len(), maxBuckets)
+	}
+	if _, ok := l.buckets["first"]; ok {
+		t.Error("the least recently seen client should be forgotten")
+	}
+	if l.allow("recent") {
+		t.Error("the bucket of a recently seen client should be kept")
+	}
+}
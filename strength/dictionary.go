@@ -0,0 +1,110 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strength
+
+import (
+	"embed"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/ntk148v/pwgenie/generator"
+)
+
+// The frequency lists come from zxcvbn <https://github.com/dropbox/zxcvbn>
+// (MIT License), most frequent entry first. The list of common passwords
+// is the one of the blocklist of the generator package.
+//
+//go:embed data/*.txt
+var dataFS embed.FS
+
+// rankedDictionary maps a lowercase word to its rank: the number of guesses
+// an attacker trying the dictionary in order needs to find it.
+type rankedDictionary struct {
+	ranks map[string]int
+	// maxLength is the length in runes of the longest word, which bounds
+	// the substrings worth looking up.
+	maxLength int
+}
+
+// newRankedDictionary returns an empty dictionary for n words.
+func newRankedDictionary(n int) rankedDictionary {
+	return rankedDictionary{ranks: make(map[string]int, n)}
+}
+
+// add ranks word, keeping the first rank of a word listed twice.
+func (d *rankedDictionary) add(word string, rank int) {
+	if _, ok := d.ranks[word]; ok {
+		return
+	}
+	d.ranks[word] = rank
+	if n := utf8.RuneCountInString(word); n > d.maxLength {
+		d.maxLength = n
+	}
+}
+
+var (
+	dictionariesOnce sync.Once
+	dictionaries     map[string]rankedDictionary
+)
+
+// loadDictionaries returns the built-in dictionaries by name.
+func loadDictionaries() map[string]rankedDictionary {
+	dictionariesOnce.Do(func() {
+		dictionaries = map[string]rankedDictionary{
+			"passwords":    rankedList(generator.CommonPasswords()),
+			"english":      frequencyList("data/english.txt"),
+			"female_names": frequencyList("data/female_names.txt"),
+			"male_names":   frequencyList("data/male_names.txt"),
+			"surnames":     frequencyList("data/surnames.txt"),
+		}
+		// Words of the wordlists of human passwords are equally likely,
+		// so an attacker needs up to the size of the list to guess one.
+		for _, w := range generator.Wordlists() {
+			dictionaries[w.Name] = uniformList(w.Words)
+		}
+	})
+	return dictionaries
+}
+
+// frequencyList loads an embedded newline-separated list ordered by
+// frequency.
+func frequencyList(name string) rankedDictionary {
+	b, err := dataFS.ReadFile(name)
+	if err != nil {
+		panic(err)
+	}
+
+	return rankedList(strings.Split(strings.TrimSpace(string(b)), "\n"))
+}
+
+// rankedList ranks every word of a list ordered by frequency with its
+// position.
+func rankedList(words []string) rankedDictionary {
+	result := newRankedDictionary(len(words))
+	for i, w := range words {
+		result.add(w, i+1)
+	}
+	return result
+}
+
+// uniformList ranks every word of the list with the size of the list.
+func uniformList(words []string) rankedDictionary {
+	result := newRankedDictionary(len(words))
+	for _, w := range words {
+		result.add(strings.ToLower(w), len(words))
+	}
+	return result
+}
@@ -0,0 +1,157 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strength
+
+import (
+	"strings"
+	"sync"
+)
+
+// Keyboard layouts, one token per key, each row indented by one more
+// character than the previous one on slanted keyboards.
+const (
+	qwertyLayout = `
+` + "`~" + ` 1! 2@ 3# 4$ 5% 6^ 7& 8* 9( 0) -_ =+
+    qQ wW eE rR tT yY uU iI oO pP [{ ]} \|
+     aA sS dD fF gG hH jJ kK lL ;: '"
+      zZ xX cC vV bB nN mM ,< .> /?
+`
+
+	dvorakLayout = `
+` + "`~" + ` 1! 2@ 3# 4$ 5% 6^ 7& 8* 9( 0) [{ ]}
+    '" ,< .> pP yY fF gG cC rR lL /? =+ \|
+     aA oO eE uU iI dD hH tT nN sS -_
+      ;: qQ jJ kK xX bB mM wW vV zZ
+`
+
+	keypadLayout = `
+  / * -
+7 8 9 +
+4 5 6
+1 2 3
+  0 .
+`
+
+	macKeypadLayout = `
+  = / *
+7 8 9 -
+4 5 6 +
+1 2 3
+  0 .
+`
+)
+
+// adjacencyGraph maps a character to the keys around its key. The position
+// of a neighbor in the slice is its direction; an empty string marks a
+// missing key. Inside a key token, index 1 is the shifted character.
+type adjacencyGraph map[rune][]string
+
+// startingPositions returns the number of keys of the graph.
+func (g adjacencyGraph) startingPositions() float64 {
+	return float64(len(g))
+}
+
+// averageDegree returns the average number of neighbors of a key.
+func (g adjacencyGraph) averageDegree() float64 {
+	var total int
+	for _, adj := range g {
+		for _, a := range adj {
+			if a != "" {
+				total++
+			}
+		}
+	}
+	return float64(total) / float64(len(g))
+}
+
+var (
+	graphsOnce sync.Once
+	graphs     map[string]adjacencyGraph
+)
+
+// loadGraphs returns the keyboard graphs by name.
+func loadGraphs() map[string]adjacencyGraph {
+	graphsOnce.Do(func() {
+		graphs = map[string]adjacencyGraph{
+			"qwerty":     buildGraph(qwertyLayout, true),
+			"dvorak":     buildGraph(dvorakLayout, true),
+			"keypad":     buildGraph(keypadLayout, false),
+			"mac_keypad": buildGraph(macKeypadLayout, false),
+		}
+	})
+	return graphs
+}
+
+type position struct{ x, y int }
+
+// buildGraph builds the adjacency graph of a layout the same way zxcvbn
+// does: keys of a slanted keyboard have 6 neighbors, keys of an aligned
+// keypad have 8.
+func buildGraph(layout string, slanted bool) adjacencyGraph {
+	lines := strings.Split(layout, "\n")
+	tokens := make(map[position]string)
+
+	var tokenSize int
+	for y, line := range lines {
+		slant := 0
+		if slanted {
+			slant = y - 1
+		}
+
+		for x := 0; x < len(line); {
+			if line[x] == ' ' {
+				x++
+				continue
+			}
+
+			end := strings.IndexByte(line[x:], ' ')
+			if end < 0 {
+				end = len(line) - x
+			}
+			token := line[x : x+end]
+			if tokenSize == 0 {
+				tokenSize = len(token)
+			}
+			tokens[position{(x - slant) / (tokenSize + 1), y}] = token
+			x += end
+		}
+	}
+
+	g := make(adjacencyGraph)
+	for pos, token := range tokens {
+		var neighbors []position
+		if slanted {
+			neighbors = []position{
+				{pos.x - 1, pos.y}, {pos.x, pos.y - 1}, {pos.x + 1, pos.y - 1},
+				{pos.x + 1, pos.y}, {pos.x, pos.y + 1}, {pos.x - 1, pos.y + 1},
+			}
+		} else {
+			neighbors = []position{
+				{pos.x - 1, pos.y}, {pos.x - 1, pos.y - 1}, {pos.x, pos.y - 1}, {pos.x + 1, pos.y - 1},
+				{pos.x + 1, pos.y}, {pos.x + 1, pos.y + 1}, {pos.x, pos.y + 1}, {pos.x - 1, pos.y + 1},
+			}
+		}
+
+		adj := make([]string, len(neighbors))
+		for i, n := range neighbors {
+			adj[i] = tokens[n]
+		}
+		for _, ch := range token {
+			g[ch] = adj
+		}
+	}
+
+	return g
+}
@@ -0,0 +1,663 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strength
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Patterns recognized by the matchers.
+const (
+	PatternDictionary = "dictionary"
+	PatternSpatial    = "spatial"
+	PatternRepeat     = "repeat"
+	PatternSequence   = "sequence"
+	PatternDate       = "date"
+	PatternYear       = "year"
+	PatternBruteforce = "bruteforce"
+)
+
+// Match is a part of a password recognized by a pattern.
+type Match struct {
+	// Pattern is the kind of match, e.g. PatternDictionary.
+	Pattern string
+	// I and J are the rune offsets of the first and last characters of
+	// Token in the password.
+	I, J  int
+	Token string
+	// Guesses is the number of guesses needed to find Token with the
+	// pattern, GuessesLog10 its base-10 logarithm.
+	Guesses      float64
+	GuessesLog10 float64
+
+	// Dictionary matches.
+	Dictionary  string
+	MatchedWord string
+	Rank        int
+	Reversed    bool
+	L33t        bool
+	// Substitutions maps the leetspeak characters of Token to the
+	// letters they stand for.
+	Substitutions map[rune]rune
+
+	// Spatial matches.
+	Graph   string
+	Turns   int
+	Shifted int
+
+	// Repeat matches.
+	BaseToken   string
+	BaseGuesses float64
+	RepeatCount int
+
+	// Sequence matches.
+	SequenceName string
+	Ascending    bool
+
+	// Date and year matches.
+	Year, Month, Day int
+	Separator        string
+}
+
+// l33tTable maps a letter to the characters commonly substituted for it.
+var l33tTable = map[rune][]rune{
+	'a': {'4', '@'},
+	'b': {'8'},
+	'c': {'(', '{', '[', '<'},
+	'e': {'3'},
+	'g': {'6', '9'},
+	'i': {'1', '!', '|'},
+	'l': {'1', '|', '7'},
+	'o': {'0'},
+	's': {'$', '5'},
+	't': {'+', '7'},
+	'x': {'%'},
+	'z': {'2'},
+}
+
+// maxL33tSubs bounds the number of substitution tables l33tMatch tries.
+const maxL33tSubs = 256
+
+// omnimatch returns every match of every pattern in the password.
+func omnimatch(password []rune) []Match {
+	var matches []Match
+	matches = append(matches, dictionaryMatch(password)...)
+	matches = append(matches, reverseDictionaryMatch(password)...)
+	matches = append(matches, l33tMatch(password)...)
+	matches = append(matches, spatialMatch(password)...)
+	matches = append(matches, repeatMatch(password)...)
+	matches = append(matches, sequenceMatch(password)...)
+	matches = append(matches, yearMatch(password)...)
+	matches = append(matches, dateMatch(password)...)
+
+	sort.SliceStable(matches, func(a, b int) bool {
+		if matches[a].I != matches[b].I {
+			return matches[a].I < matches[b].I
+		}
+		return matches[a].J < matches[b].J
+	})
+	return matches
+}
+
+// dictionaryMatch finds every substring of the password present in a
+// built-in dictionary.
+func dictionaryMatch(password []rune) []Match {
+	var (
+		matches []Match
+		lower   = []rune(strings.ToLower(string(password)))
+	)
+	if len(lower) != len(password) {
+		// Lowercasing changed the length, offsets would not line up.
+		lower = password
+	}
+
+	for name, dict := range loadDictionaries() {
+		for i := range lower {
+			for j := i; j < len(lower) && j-i < dict.maxLength; j++ {
+				word := string(lower[i : j+1])
+				if rank, ok := dict.ranks[word]; ok {
+					matches = append(matches, Match{
+						Pattern:     PatternDictionary,
+						I:           i,
+						J:           j,
+						Token:       string(password[i : j+1]),
+						Dictionary:  name,
+						MatchedWord: word,
+						Rank:        rank,
+					})
+				}
+			}
+		}
+	}
+
+	return matches
+}
+
+// reverseDictionaryMatch finds dictionary words written backwards.
+func reverseDictionaryMatch(password []rune) []Match {
+	reversed := reverseRunes(password)
+
+	var matches []Match
+	for _, m := range dictionaryMatch(reversed) {
+		token := reverseRunes([]rune(m.Token))
+		if string(token) == m.Token {
+			// Palindromes are already found as regular words.
+			continue
+		}
+		m.Token = string(token)
+		m.I, m.J = len(password)-1-m.J, len(password)-1-m.I
+		m.Reversed = true
+		matches = append(matches, m)
+	}
+
+	return matches
+}
+
+// l33tMatch finds dictionary words written in leetspeak, e.g. "p4ssw0rd".
+func l33tMatch(password []rune) []Match {
+	var matches []Match
+	for _, sub := range l33tSubs(password) {
+		subbed := make([]rune, len(password))
+		for i, ch := range password {
+			if letter, ok := sub[ch]; ok {
+				subbed[i] = letter
+			} else {
+				subbed[i] = ch
+			}
+		}
+
+		for _, m := range dictionaryMatch(subbed) {
+			token := password[m.I : m.J+1]
+			if len(token) <= 1 || strings.ToLower(string(token)) == m.MatchedWord {
+				// Single characters and tokens without substitutions are
+				// not l33t.
+				continue
+			}
+
+			used := make(map[rune]rune)
+			for _, ch := range token {
+				if letter, ok := sub[ch]; ok {
+					used[ch] = letter
+				}
+			}
+			m.Token = string(token)
+			m.L33t = true
+			m.Substitutions = used
+			matches = append(matches, m)
+		}
+	}
+
+	return dedupMatches(matches)
+}
+
+// l33tSubs enumerates the substitution tables that apply to the password:
+// each leetspeak character present maps to one of the letters it may stand
+// for.
+func l33tSubs(password []rune) []map[rune]rune {
+	candidates := make(map[rune][]rune)
+	for letter, subs := range l33tTable {
+		for _, s := range subs {
+			if containsRune(password, s) {
+				candidates[s] = append(candidates[s], letter)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	chars := make([]rune, 0, len(candidates))
+	for ch := range candidates {
+		chars = append(chars, ch)
+		sort.Slice(candidates[ch], func(a, b int) bool { return candidates[ch][a] < candidates[ch][b] })
+	}
+	sort.Slice(chars, func(a, b int) bool { return chars[a] < chars[b] })
+
+	result := []map[rune]rune{{}}
+	for _, ch := range chars {
+		var next []map[rune]rune
+		for _, sub := range result {
+			for _, letter := range candidates[ch] {
+				if len(next) == maxL33tSubs {
+					break
+				}
+				s := make(map[rune]rune, len(sub)+1)
+				for k, v := range sub {
+					s[k] = v
+				}
+				s[ch] = letter
+				next = append(next, s)
+			}
+		}
+		result = next
+	}
+
+	return result
+}
+
+// shiftedRx matches the characters typed with shift on a qwerty keyboard.
+var shiftedRx = regexp.MustCompile(`[~!@#$%^&*()_+QWERTYUIOPASDFGHJKL:"ZXCVBNM<>?]`)
+
+// spatialMatch finds keyboard walks such as "qwerty" or "zxcvbn".
+func spatialMatch(password []rune) []Match {
+	var matches []Match
+	for name, graph := range loadGraphs() {
+		matches = append(matches, spatialMatchHelper(password, graph, name)...)
+	}
+	return matches
+}
+
+func spatialMatchHelper(password []rune, graph adjacencyGraph, name string) []Match {
+	var matches []Match
+	keyboard := name == "qwerty" || name == "dvorak"
+
+	for i := 0; i < len(password)-1; {
+		var (
+			j             = i + 1
+			lastDirection = -1
+			turns         int
+			shifted       int
+		)
+		if keyboard && shiftedRx.MatchString(string(password[i])) {
+			shifted = 1
+		}
+
+		for {
+			found := false
+			if j < len(password) {
+				cur := string(password[j])
+				for direction, adj := range graph[password[j-1]] {
+					idx := strings.Index(adj, cur)
+					if adj == "" || idx < 0 {
+						continue
+					}
+
+					found = true
+					if idx > 0 {
+						shifted++
+					}
+					if direction != lastDirection {
+						turns++
+						lastDirection = direction
+					}
+					break
+				}
+			}
+
+			if found {
+				j++
+				continue
+			}
+
+			if j-i > 2 {
+				matches = append(matches, Match{
+					Pattern: PatternSpatial,
+					I:       i,
+					J:       j - 1,
+					Token:   string(password[i:j]),
+					Graph:   name,
+					Turns:   turns,
+					Shifted: shifted,
+				})
+			}
+			i = j
+			break
+		}
+	}
+
+	return matches
+}
+
+// repeatMatch finds repeated substrings such as "aaa" or "abcabc". At each
+// position it picks the base covering the longest span.
+func repeatMatch(password []rune) []Match {
+	var matches []Match
+	for i := 0; i < len(password)-1; {
+		bestBase, bestCount := 0, 0
+		for base := 1; i+2*base <= len(password); base++ {
+			count := 1
+			for i+(count+1)*base <= len(password) &&
+				string(password[i+count*base:i+(count+1)*base]) == string(password[i:i+base]) {
+				count++
+			}
+			if count > 1 && base*count > bestBase*bestCount {
+				bestBase, bestCount = base, count
+			}
+		}
+
+		if bestCount == 0 {
+			i++
+			continue
+		}
+
+		j := i + bestBase*bestCount - 1
+		baseToken := string(password[i : i+bestBase])
+		matches = append(matches, Match{
+			Pattern:     PatternRepeat,
+			I:           i,
+			J:           j,
+			Token:       string(password[i : j+1]),
+			BaseToken:   baseToken,
+			RepeatCount: bestCount,
+			BaseGuesses: mostGuessableMatchSequence([]rune(baseToken), omnimatch([]rune(baseToken))).guesses,
+		})
+		i = j + 1
+	}
+
+	return matches
+}
+
+// maxSequenceDelta is the largest step between characters of a sequence.
+const maxSequenceDelta = 5
+
+// sequenceMatch finds runs of characters with a constant step, such as
+// "abcdef", "13579" or "9876".
+func sequenceMatch(password []rune) []Match {
+	if len(password) <= 1 {
+		return nil
+	}
+
+	var matches []Match
+	update := func(i, j, delta int) {
+		if abs(delta) == 0 || abs(delta) > maxSequenceDelta || (j-i <= 1 && abs(delta) != 1) {
+			return
+		}
+
+		token := password[i : j+1]
+		name := "unicode"
+		switch {
+		case allRunes(token, unicode.IsLower):
+			name = "lower"
+		case allRunes(token, unicode.IsUpper):
+			name = "upper"
+		case allRunes(token, unicode.IsDigit):
+			name = "digits"
+		}
+		matches = append(matches, Match{
+			Pattern:      PatternSequence,
+			I:            i,
+			J:            j,
+			Token:        string(token),
+			SequenceName: name,
+			Ascending:    delta > 0,
+		})
+	}
+
+	i, lastDelta := 0, int(password[1]-password[0])
+	for k := 1; k < len(password); k++ {
+		delta := int(password[k] - password[k-1])
+		if delta == lastDelta {
+			continue
+		}
+		update(i, k-1, lastDelta)
+		i, lastDelta = k-1, delta
+	}
+	update(i, len(password)-1, lastDelta)
+
+	return matches
+}
+
+// yearRx matches recent years.
+var yearRx = regexp.MustCompile(`19\d\d|20\d\d`)
+
+// yearMatch finds recent years such as "1987" or "2023".
+func yearMatch(password []rune) []Match {
+	var matches []Match
+	s := string(password)
+	for _, loc := range yearRx.FindAllStringIndex(s, -1) {
+		year, _ := strconv.Atoi(s[loc[0]:loc[1]])
+		i := len([]rune(s[:loc[0]]))
+		matches = append(matches, Match{
+			Pattern: PatternYear,
+			I:       i,
+			J:       i + 3,
+			Token:   s[loc[0]:loc[1]],
+			Year:    year,
+		})
+	}
+	return matches
+}
+
+// Limits of the years recognized in dates.
+const (
+	dateMinYear = 1000
+	dateMaxYear = 2050
+)
+
+// dateSplits lists, by length, where to split a date without separators
+// into its three parts.
+var dateSplits = map[int][][2]int{
+	4: {{1, 2}, {2, 3}},         // 1 1 91 | 1 11 1
+	5: {{1, 3}, {2, 3}},         // 1 11 91 | 11 1 91
+	6: {{1, 2}, {2, 4}, {4, 5}}, // 1 1 1991 | 11 11 91 | 1991 1 1
+	7: {{1, 3}, {2, 3}, {4, 5}, {4, 6}},
+	8: {{2, 4}, {4, 6}}, // 11 11 1991 | 1991 11 11
+}
+
+// dateSepRx matches a date with separators such as "1/1/91" or "1991-11-11".
+var dateSepRx = regexp.MustCompile(`^(\d{1,4})([\s/\\_.-])(\d{1,2})([\s/\\_.-])(\d{1,4})$`)
+
+// dateMatch finds dates, with or without separators.
+func dateMatch(password []rune) []Match {
+	var (
+		matches   []Match
+		reference = time.Now().Year()
+	)
+
+	for i := range password {
+		for j := i + 3; j < len(password) && j <= i+9; j++ {
+			token := string(password[i : j+1])
+
+			if splits, ok := dateSplits[len(token)]; ok && allRunes(password[i:j+1], isASCIIDigit) {
+				var (
+					best     date
+					bestDist = -1
+				)
+				for _, s := range splits {
+					ints := [3]int{atoi(token[:s[0]]), atoi(token[s[0]:s[1]]), atoi(token[s[1]:])}
+					if d, ok := mapIntsToDMY(ints); ok {
+						if dist := abs(d.year - reference); bestDist < 0 || dist < bestDist {
+							best, bestDist = d, dist
+						}
+					}
+				}
+				if bestDist >= 0 {
+					matches = append(matches, Match{
+						Pattern: PatternDate, I: i, J: j, Token: token,
+						Year: best.year, Month: best.month, Day: best.day,
+					})
+				}
+				continue
+			}
+
+			if len(token) < 6 {
+				continue
+			}
+			parts := dateSepRx.FindStringSubmatch(token)
+			if parts == nil || parts[2] != parts[4] {
+				continue
+			}
+			if d, ok := mapIntsToDMY([3]int{atoi(parts[1]), atoi(parts[3]), atoi(parts[5])}); ok {
+				matches = append(matches, Match{
+					Pattern: PatternDate, I: i, J: j, Token: token, Separator: parts[2],
+					Year: d.year, Month: d.month, Day: d.day,
+				})
+			}
+		}
+	}
+
+	// Drop dates contained in a longer date, e.g. "1/1/91" in "1/1/1991".
+	var result []Match
+	for a, m := range matches {
+		contained := false
+		for b, o := range matches {
+			if a != b && o.I <= m.I && o.J >= m.J && (o.I != m.I || o.J != m.J) {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			result = append(result, m)
+		}
+	}
+
+	return result
+}
+
+type date struct{ year, month, day int }
+
+// mapIntsToDMY interprets three integers as a day, a month and a year in
+// any common order.
+func mapIntsToDMY(ints [3]int) (date, bool) {
+	if ints[1] > 31 || ints[1] <= 0 {
+		return date{}, false
+	}
+
+	var over12, over31, under1 int
+	for _, n := range ints {
+		if (n > 99 && n < dateMinYear) || n > dateMaxYear {
+			return date{}, false
+		}
+		if n > 31 {
+			over31++
+		}
+		if n > 12 {
+			over12++
+		}
+		if n <= 0 {
+			under1++
+		}
+	}
+	if over31 >= 2 || over12 == 3 || under1 >= 2 {
+		return date{}, false
+	}
+
+	splits := [2]struct {
+		year int
+		rest [2]int
+	}{
+		{ints[2], [2]int{ints[0], ints[1]}},
+		{ints[0], [2]int{ints[1], ints[2]}},
+	}
+
+	for _, s := range splits {
+		if s.year >= dateMinYear && s.year <= dateMaxYear {
+			if d, ok := mapIntsToDM(s.rest); ok {
+				d.year = s.year
+				return d, true
+			}
+			// A four-digit year with an invalid day and month.
+			return date{}, false
+		}
+	}
+
+	for _, s := range splits {
+		if d, ok := mapIntsToDM(s.rest); ok {
+			d.year = twoToFourDigitYear(s.year)
+			return d, true
+		}
+	}
+
+	return date{}, false
+}
+
+// mapIntsToDM interprets two integers as a day and a month in any order.
+func mapIntsToDM(ints [2]int) (date, bool) {
+	for _, dm := range [2][2]int{ints, {ints[1], ints[0]}} {
+		if dm[0] >= 1 && dm[0] <= 31 && dm[1] >= 1 && dm[1] <= 12 {
+			return date{day: dm[0], month: dm[1]}, true
+		}
+	}
+	return date{}, false
+}
+
+// twoToFourDigitYear expands a two-digit year to the closest century.
+func twoToFourDigitYear(year int) int {
+	switch {
+	case year > 99:
+		return year
+	case year > 50:
+		return 1900 + year
+	default:
+		return 2000 + year
+	}
+}
+
+// dedupMatches removes matches with the same span and matched word.
+func dedupMatches(matches []Match) []Match {
+	type key struct {
+		i, j int
+		dict string
+		word string
+	}
+
+	seen := make(map[key]bool)
+	var result []Match
+	for _, m := range matches {
+		k := key{m.I, m.J, m.Dictionary, m.MatchedWord}
+		if !seen[k] {
+			seen[k] = true
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+func reverseRunes(s []rune) []rune {
+	result := make([]rune, len(s))
+	for i, ch := range s {
+		result[len(s)-1-i] = ch
+	}
+	return result
+}
+
+func containsRune(s []rune, ch rune) bool {
+	for _, c := range s {
+		if c == ch {
+			return true
+		}
+	}
+	return false
+}
+
+func allRunes(s []rune, f func(rune) bool) bool {
+	for _, ch := range s {
+		if !f(ch) {
+			return false
+		}
+	}
+	return true
+}
+
+func isASCIIDigit(ch rune) bool {
+	return ch >= '0' && ch <= '9'
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
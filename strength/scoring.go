@@ -0,0 +1,387 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strength
+
+import (
+	"math"
+	"regexp"
+	"time"
+	"unicode"
+)
+
+// Constants of the zxcvbn guess estimation.
+const (
+	bruteforceCardinality           = 10
+	minGuessesBeforeGrowingSequence = 10000
+	minSubmatchGuessesSingleChar    = 10
+	minSubmatchGuessesMultiChar     = 50
+	minYearSpace                    = 20
+)
+
+// guessSequence is the most likely way an attacker finds a password: the
+// sequence of matches covering it and the number of guesses needed.
+type guessSequence struct {
+	guesses      float64
+	guessesLog10 float64
+	sequence     []Match
+}
+
+// optimalStep is the best sequence of l matches ending at a position.
+type optimalStep struct {
+	match Match
+	// pi is the log10 of the product of the guesses of the matches.
+	pi float64
+	// g is the log10 of the guesses of the whole sequence.
+	g float64
+}
+
+// mostGuessableMatchSequence searches the sequence of non-overlapping
+// matches covering the password that needs the fewest guesses, filling
+// the gaps with bruteforce matches. A sequence of l matches costs
+// l! * product(guesses) + minGuessesBeforeGrowingSequence^(l-1) guesses,
+// which favors short sequences.
+func mostGuessableMatchSequence(password []rune, matches []Match) guessSequence {
+	n := len(password)
+	if n == 0 {
+		return guessSequence{guesses: 1}
+	}
+
+	byJ := make([][]Match, n)
+	for _, m := range matches {
+		byJ[m.J] = append(byJ[m.J], m)
+	}
+
+	// optimal[k][l] is the best sequence of l matches covering
+	// password[:k+1].
+	optimal := make([]map[int]optimalStep, n)
+	for k := range optimal {
+		optimal[k] = make(map[int]optimalStep)
+	}
+
+	update := func(m Match, l int) {
+		k := m.J
+		pi := estimateGuessesLog10(&m, n)
+		if l > 1 {
+			pi += optimal[m.I-1][l-1].pi
+		}
+		g := log10Add(log10Factorial(l)+pi, float64(l-1)*math.Log10(minGuessesBeforeGrowingSequence))
+
+		for cl, c := range optimal[k] {
+			if cl <= l && c.g <= g {
+				return
+			}
+		}
+		optimal[k][l] = optimalStep{match: m, pi: pi, g: g}
+	}
+
+	bruteforceUpdate := func(k int) {
+		update(bruteforceMatch(password, 0, k), 1)
+		for i := 1; i <= k; i++ {
+			m := bruteforceMatch(password, i, k)
+			for l, last := range optimal[i-1] {
+				// Two adjacent bruteforce matches are one longer match.
+				if last.match.Pattern != PatternBruteforce {
+					update(m, l+1)
+				}
+			}
+		}
+	}
+
+	for k := 0; k < n; k++ {
+		for _, m := range byJ[k] {
+			if m.I > 0 {
+				for l := range optimal[m.I-1] {
+					update(m, l+1)
+				}
+			} else {
+				update(m, 1)
+			}
+		}
+		bruteforceUpdate(k)
+	}
+
+	// Unwind the best sequence from the end of the password.
+	bestL, bestG := 0, math.Inf(1)
+	for l, c := range optimal[n-1] {
+		if c.g < bestG || (c.g == bestG && l < bestL) {
+			bestL, bestG = l, c.g
+		}
+	}
+
+	sequence := make([]Match, bestL)
+	for k, l := n-1, bestL; k >= 0 && l > 0; l-- {
+		m := optimal[k][l].match
+		sequence[l-1] = m
+		k = m.I - 1
+	}
+
+	return guessSequence{
+		guesses:      math.Pow(10, bestG),
+		guessesLog10: bestG,
+		sequence:     sequence,
+	}
+}
+
+// bruteforceMatch returns the match of password[i:j+1] by bruteforce.
+func bruteforceMatch(password []rune, i, j int) Match {
+	return Match{
+		Pattern: PatternBruteforce,
+		I:       i,
+		J:       j,
+		Token:   string(password[i : j+1]),
+	}
+}
+
+// estimateGuessesLog10 sets and returns the log10 of the number of guesses
+// needed to find the match in a password of the given length.
+func estimateGuessesLog10(m *Match, passwordLen int) float64 {
+	if m.GuessesLog10 != 0 {
+		return m.GuessesLog10
+	}
+
+	tokenLen := len([]rune(m.Token))
+	minGuesses := 1.0
+	if tokenLen < passwordLen {
+		minGuesses = minSubmatchGuessesMultiChar
+		if tokenLen == 1 {
+			minGuesses = minSubmatchGuessesSingleChar
+		}
+	}
+
+	var guesses float64
+	switch m.Pattern {
+	case PatternBruteforce:
+		// 10^len may overflow, so stay in log space.
+		g := float64(tokenLen) * math.Log10(bruteforceCardinality)
+		minBruteforce := 1.0
+		if tokenLen < passwordLen {
+			minBruteforce = minSubmatchGuessesMultiChar + 1
+			if tokenLen == 1 {
+				minBruteforce = minSubmatchGuessesSingleChar + 1
+			}
+		}
+		g = math.Max(g, math.Log10(minBruteforce))
+		m.GuessesLog10, m.Guesses = g, math.Pow(10, g)
+		return g
+	case PatternDictionary:
+		guesses = dictionaryGuesses(m)
+	case PatternSpatial:
+		guesses = spatialGuesses(m)
+	case PatternRepeat:
+		guesses = m.BaseGuesses * float64(m.RepeatCount)
+	case PatternSequence:
+		guesses = sequenceGuesses(m)
+	case PatternYear:
+		guesses = yearSpace(m.Year)
+	case PatternDate:
+		guesses = yearSpace(m.Year) * 365
+		if m.Separator != "" {
+			guesses *= 4
+		}
+	}
+
+	guesses = math.Max(guesses, minGuesses)
+	m.Guesses, m.GuessesLog10 = guesses, math.Log10(guesses)
+	return m.GuessesLog10
+}
+
+func dictionaryGuesses(m *Match) float64 {
+	guesses := float64(m.Rank) * uppercaseVariations(m.Token) * l33tVariations(m)
+	if m.Reversed {
+		guesses *= 2
+	}
+	return guesses
+}
+
+var (
+	startUpperRx = regexp.MustCompile(`^\p{Lu}[^\p{Lu}]+$`)
+	endUpperRx   = regexp.MustCompile(`^[^\p{Lu}]+\p{Lu}$`)
+	allUpperRx   = regexp.MustCompile(`^[^\p{Ll}]+$`)
+)
+
+// uppercaseVariations returns the number of ways the letters of a word may
+// have been capitalized to get token.
+func uppercaseVariations(token string) float64 {
+	if !containsFunc(token, unicode.IsUpper) {
+		return 1
+	}
+	// A capitalized first or last letter, or all caps, are common enough
+	// that they only double the guesses.
+	for _, rx := range []*regexp.Regexp{startUpperRx, endUpperRx, allUpperRx} {
+		if rx.MatchString(token) {
+			return 2
+		}
+	}
+
+	var upper, lower int
+	for _, ch := range token {
+		switch {
+		case unicode.IsUpper(ch):
+			upper++
+		case unicode.IsLower(ch):
+			lower++
+		}
+	}
+	return variations(upper, lower)
+}
+
+// l33tVariations returns the number of ways the substitutions of a l33t
+// match may have been applied to the word.
+func l33tVariations(m *Match) float64 {
+	if !m.L33t {
+		return 1
+	}
+
+	result := 1.0
+	for subbed, letter := range m.Substitutions {
+		var s, u int
+		for _, ch := range m.Token {
+			switch unicode.ToLower(ch) {
+			case subbed:
+				s++
+			case letter:
+				u++
+			}
+		}
+		if s == 0 || u == 0 {
+			// Either every occurrence is substituted or none is: the
+			// attacker only has to try both.
+			result *= 2
+		} else {
+			result *= variations(s, u)
+		}
+	}
+	return result
+}
+
+// spatialGuesses counts the keyboard walks of the same length with at most
+// the same number of turns.
+func spatialGuesses(m *Match) float64 {
+	graph := loadGraphs()[m.Graph]
+	var (
+		s       = graph.startingPositions()
+		d       = graph.averageDegree()
+		l       = len([]rune(m.Token))
+		guesses float64
+	)
+
+	for i := 2; i <= l; i++ {
+		possibleTurns := m.Turns
+		if i-1 < possibleTurns {
+			possibleTurns = i - 1
+		}
+		for j := 1; j <= possibleTurns; j++ {
+			guesses += nCk(i-1, j-1) * s * math.Pow(d, float64(j))
+		}
+	}
+
+	if m.Shifted > 0 {
+		unshifted := l - m.Shifted
+		if unshifted == 0 {
+			guesses *= 2
+		} else {
+			guesses *= variations(m.Shifted, unshifted)
+		}
+	}
+
+	return guesses
+}
+
+// sequenceGuesses rates obvious starts like "a", "z", "0" or "1" lower.
+func sequenceGuesses(m *Match) float64 {
+	var (
+		first = []rune(m.Token)[0]
+		base  float64
+	)
+	switch {
+	case first == 'a' || first == 'A' || first == 'z' || first == 'Z' ||
+		first == '0' || first == '1' || first == '9':
+		base = 4
+	case unicode.IsDigit(first):
+		base = 10
+	default:
+		// Letters and other characters.
+		base = 26
+	}
+	if !m.Ascending {
+		base *= 2
+	}
+	return base * float64(len([]rune(m.Token)))
+}
+
+// yearSpace returns the number of years an attacker tries before reaching
+// year, counting from the current year.
+func yearSpace(year int) float64 {
+	space := abs(year - time.Now().Year())
+	if space < minYearSpace {
+		space = minYearSpace
+	}
+	return float64(space)
+}
+
+// variations returns the sum of C(a+b, i) for i from 1 to min(a, b).
+func variations(a, b int) float64 {
+	limit := a
+	if b < limit {
+		limit = b
+	}
+
+	var result float64
+	for i := 1; i <= limit; i++ {
+		result += nCk(a+b, i)
+	}
+	return result
+}
+
+// nCk returns the binomial coefficient of n and k.
+func nCk(n, k int) float64 {
+	if k > n {
+		return 0
+	}
+	if k == 0 {
+		return 1
+	}
+
+	r := 1.0
+	for d := 1; d <= k; d++ {
+		r *= float64(n)
+		r /= float64(d)
+		n--
+	}
+	return r
+}
+
+// log10Factorial returns log10(n!).
+func log10Factorial(n int) float64 {
+	lf, _ := math.Lgamma(float64(n) + 1)
+	return lf / math.Ln10
+}
+
+// log10Add returns log10(10^a + 10^b).
+func log10Add(a, b float64) float64 {
+	if a < b {
+		a, b = b, a
+	}
+	return a + math.Log10(1+math.Pow(10, b-a))
+}
+
+func containsFunc(s string, f func(rune) bool) bool {
+	for _, ch := range s {
+		if f(ch) {
+			return true
+		}
+	}
+	return false
+}
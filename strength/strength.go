@@ -0,0 +1,172 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package strength estimates the strength of passwords chosen by people.
+//
+// It is a port of the ideas of zxcvbn <https://github.com/dropbox/zxcvbn>:
+// a password is split into the patterns an attacker would try first, such
+// as dictionary words, keyboard walks, repeats, sequences, dates and
+// leetspeak, and the number of guesses needed to find it is estimated from
+// the cheapest combination of those patterns.
+package strength
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/ntk148v/pwgenie/generator"
+)
+
+// AttackModel is an attacker able to try a fixed number of guesses per
+// second.
+type AttackModel struct {
+	Name             string
+	GuessesPerSecond float64
+}
+
+// AttackModels are the attackers crack times are estimated for, from the
+// slowest to the fastest.
+var AttackModels = []AttackModel{
+	{Name: "online attack, throttled (100/hour)", GuessesPerSecond: 100.0 / 3600},
+	{Name: "online attack, unthrottled (10/second)", GuessesPerSecond: 10},
+	{Name: "offline attack, slow hash (1e4/second)", GuessesPerSecond: 1e4},
+	{Name: "offline attack, fast hash (1e10/second)", GuessesPerSecond: 1e10},
+}
+
+// CrackTime is the time an attacker needs to guess a password.
+type CrackTime struct {
+	AttackModel
+	Seconds float64
+}
+
+// String returns the crack time in a human-readable form.
+func (c CrackTime) String() string {
+	return DisplayTime(c.Seconds)
+}
+
+// Result is the strength estimate of a password.
+type Result struct {
+	// Guesses is the estimated number of guesses needed to find the
+	// password, GuessesLog10 its base-10 logarithm.
+	Guesses      float64
+	GuessesLog10 float64
+	// Score rates the password from 0 (too guessable) to 4 (very
+	// unguessable).
+	Score int
+	// CrackTimes are the times needed by each of AttackModels.
+	CrackTimes []CrackTime
+	// Sequence is the list of patterns the estimate is based on.
+	Sequence []Match
+	// Blocked reports whether the password is a common password or a
+	// notorious PIN code of the blocklist of generated passwords.
+	Blocked bool
+}
+
+// maxCheckLength is the number of runes of a password Check analyses, as
+// in the ports of zxcvbn: matching is quadratic in the length.
+const maxCheckLength = 100
+
+// Check estimates the strength of the password. Only its first
+// maxCheckLength runes are analysed, which underestimates longer
+// passwords.
+func Check(password string) Result {
+	runes := []rune(password)
+	if len(runes) > maxCheckLength {
+		runes = runes[:maxCheckLength]
+	}
+	seq := mostGuessableMatchSequence(runes, omnimatch(runes))
+
+	result := Result{
+		Guesses:      seq.guesses,
+		GuessesLog10: seq.guessesLog10,
+		Score:        score(seq.guesses),
+		Sequence:     seq.sequence,
+		Blocked:      generator.Blocked(password),
+	}
+	for _, a := range AttackModels {
+		result.CrackTimes = append(result.CrackTimes, CrackTime{
+			AttackModel: a,
+			Seconds:     seq.guesses / a.GuessesPerSecond,
+		})
+	}
+
+	return result
+}
+
+// score maps a number of guesses to the 0-4 scale of zxcvbn. The small
+// delta makes the boundaries exclusive.
+func score(guesses float64) int {
+	const delta = 5
+	switch {
+	case guesses < 1e3+delta:
+		// risky password: "too guessable"
+		return 0
+	case guesses < 1e6+delta:
+		// modest protection from throttled online attacks: "very guessable"
+		return 1
+	case guesses < 1e8+delta:
+		// modest protection from unthrottled online attacks: "somewhat guessable"
+		return 2
+	case guesses < 1e10+delta:
+		// modest protection from offline attacks: "safely unguessable"
+		return 3
+	default:
+		// strong protection from offline attacks: "very unguessable"
+		return 4
+	}
+}
+
+// DisplayTime returns a duration in seconds in a human-readable form,
+// e.g. "3 hours" or "centuries".
+func DisplayTime(seconds float64) string {
+	const (
+		minute  = 60
+		hour    = 60 * minute
+		day     = 24 * hour
+		month   = 31 * day
+		year    = 12 * month
+		century = 100 * year
+	)
+
+	units := []struct {
+		name string
+		size float64
+	}{
+		{"year", year},
+		{"month", month},
+		{"day", day},
+		{"hour", hour},
+		{"minute", minute},
+		{"second", 1},
+	}
+
+	switch {
+	case seconds < 1:
+		return "less than a second"
+	case seconds >= century:
+		return "centuries"
+	}
+
+	for _, u := range units {
+		if seconds >= u.size {
+			n := math.Round(seconds / u.size)
+			if n == 1 {
+				return fmt.Sprintf("1 %s", u.name)
+			}
+			return fmt.Sprintf("%.0f %ss", n, u.name)
+		}
+	}
+
+	return "less than a second"
+}
@@ -0,0 +1,127 @@
+// Copyright 2023 Kien Nguyen-Tuan <kiennt2609@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package strength
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func hasPattern(matches []Match, pattern, token string) bool {
+	for _, m := range matches {
+		if m.Pattern == pattern && m.Token == token {
+			return true
+		}
+	}
+	return false
+}
+
+func TestMatchers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		password string
+		pattern  string
+		token    string
+	}{
+		{"xxpasswordxx", PatternDictionary, "password"},
+		{"xxdrowssapxx", PatternDictionary, "drowssap"},
+		{"p4ssw0rd", PatternDictionary, "p4ssw0rd"},
+		{"#qwerfdsa#", PatternSpatial, "qwerfdsa"},
+		{"zxcvbn", PatternSpatial, "zxcvbn"},
+		{"x7896x", PatternSpatial, "7896"},
+		{"abcabcabc", PatternRepeat, "abcabcabc"},
+		{"#13579#", PatternSequence, "13579"},
+		{"zyxw!", PatternSequence, "zyxw"},
+		{"born1987", PatternYear, "1987"},
+		{"19871231", PatternDate, "19871231"},
+		{"x31-12-87x", PatternDate, "31-12-87"},
+	}
+
+	for _, tt := range tests {
+		if !hasPattern(omnimatch([]rune(tt.password)), tt.pattern, tt.token) {
+			t.Errorf("%q: expected a %s match of %q", tt.password, tt.pattern, tt.token)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		password string
+		score    int
+		blocked  bool
+	}{
+		{"password", 0, true},
+		{"P@ssw0rd", 0, false},
+		{"123456", 0, true},
+		{"qwertyuiop", 1, true},
+		{"aaaaaaaaaaaa", 0, false},
+		{"x7$Kq!vP9z#L2", 4, false},
+		{"correcthorsebatterystaple", 4, false},
+	}
+
+	for _, tt := range tests {
+		res := Check(tt.password)
+		if res.Score != tt.score {
+			t.Errorf("%q: score is %d, want %d (%+v)", tt.password, res.Score, tt.score, res.Sequence)
+		}
+		if res.Blocked != tt.blocked {
+			t.Errorf("%q: blocked is %v, want %v", tt.password, res.Blocked, tt.blocked)
+		}
+		if len(res.CrackTimes) != len(AttackModels) {
+			t.Errorf("%q: expected %d crack times, got %d", tt.password, len(AttackModels), len(res.CrackTimes))
+		}
+	}
+}
+
+func TestCheckLongPassword(t *testing.T) {
+	t.Parallel()
+
+	// Leetspeak characters make l33tMatch look the password up once per
+	// substitution table.
+	password := strings.Repeat("p4ssw0rd$1!", 100)[:1000]
+	start := time.Now()
+	res := Check(password)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("checking %d runes took %v", len(password), elapsed)
+	}
+	if last := res.Sequence[len(res.Sequence)-1]; last.J != maxCheckLength-1 {
+		t.Errorf("the sequence ends at %d, want %d", last.J, maxCheckLength-1)
+	}
+}
+
+func TestDisplayTime(t *testing.T) {
+	t.Parallel()
+
+	tests := map[float64]string{
+		0.5:     "less than a second",
+		1:       "1 second",
+		90:      "2 minutes",
+		7200:    "2 hours",
+		86400:   "1 day",
+		1e11:    "centuries",
+		3.3e7:   "1 year",
+		5356800: "2 months",
+	}
+
+	for seconds, want := range tests {
+		if got := DisplayTime(seconds); got != want {
+			t.Errorf("%v: got %q, want %q", seconds, got, want)
+		}
+	}
+}